@@ -0,0 +1,265 @@
+package build
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/excircle/quik-version/internal/version"
+)
+
+// Options describes a container image build
+type Options struct {
+	Context       string
+	Containerfile string
+	Image         string
+	Version       string
+	BuildArgs     []string  // KEY=VALUE pairs
+	Released      []string  // versions already released, so older release lines don't move aliases
+	Output        io.Writer // where buildah output is written (default stdout)
+}
+
+// Result describes a built container image
+type Result struct {
+	ImageID      string
+	Digest       string
+	Tags         []string
+	PushedDigest string
+}
+
+// Tags returns the image references for a version: the full version plus
+// the major, major.minor and latest aliases. Pre-releases only get the full
+// version tag so they never move the aliases. An alias is only moved if no
+// released version it covers has higher precedence, so a patch to an older
+// release line does not take over the major or latest aliases.
+func Tags(image, v string, released []string) ([]string, error) {
+	parsed, err := version.Parse(v)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build metadata is not allowed in OCI tags
+	full := parsed.Core()
+	full.PreRelease = parsed.PreRelease
+
+	tags := []string{image + ":" + full.String()}
+	if parsed.IsPreRelease() {
+		return tags, nil
+	}
+
+	newestLine, newestMajor, newest := true, true, true
+	for _, r := range released {
+		other, err := version.Parse(r)
+		if err != nil || other.IsPreRelease() || !parsed.LessThan(other) {
+			continue
+		}
+		newest = false
+		if other.Major == parsed.Major {
+			newestMajor = false
+			if other.Minor == parsed.Minor {
+				newestLine = false
+			}
+		}
+	}
+
+	if newestLine {
+		tags = append(tags, fmt.Sprintf("%s:%d.%d", image, parsed.Major, parsed.Minor))
+	}
+	if newestMajor {
+		tags = append(tags, fmt.Sprintf("%s:%d", image, parsed.Major))
+	}
+	if newest {
+		tags = append(tags, image+":latest")
+	}
+	return tags, nil
+}
+
+// Available reports whether the buildah CLI is installed
+func Available() bool {
+	_, err := exec.LookPath("buildah")
+	return err == nil
+}
+
+// Build runs buildah to build the Containerfile and tag the resulting image
+func Build(ctx context.Context, opts Options) (*Result, error) {
+	if opts.Image == "" {
+		return nil, fmt.Errorf("build.image not configured")
+	}
+	if !Available() {
+		return nil, fmt.Errorf("buildah not found in PATH")
+	}
+
+	tags, err := Tags(opts.Image, opts.Version, opts.Released)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute image tags: %w", err)
+	}
+
+	// buildah writes the image ID to this file once the build succeeds
+	iidFile, err := os.CreateTemp("", "qv-iid-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create image ID file: %w", err)
+	}
+	iidFile.Close()
+	defer os.Remove(iidFile.Name())
+
+	args := []string{"build", "--iidfile", iidFile.Name(), "--file", filepath.Join(opts.Context, opts.Containerfile)}
+	for _, tag := range tags {
+		args = append(args, "--tag", tag)
+	}
+	for _, arg := range opts.BuildArgs {
+		args = append(args, "--build-arg", arg)
+	}
+	args = append(args, opts.Context)
+
+	cmd := exec.CommandContext(ctx, "buildah", args...)
+	cmd.Stdout = outputOrStdout(opts.Output)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("buildah build failed: %w", err)
+	}
+
+	iid, err := os.ReadFile(iidFile.Name())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image ID: %w", err)
+	}
+	imageID := strings.TrimPrefix(strings.TrimSpace(string(iid)), "sha256:")
+
+	digest, err := inspectDigest(ctx, imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		ImageID: imageID,
+		Digest:  digest,
+		Tags:    tags,
+	}, nil
+}
+
+// inspectDigest returns the manifest digest of a local image
+func inspectDigest(ctx context.Context, imageID string) (string, error) {
+	out, err := exec.CommandContext(ctx, "buildah", "inspect", "--type", "image", "--format", "{{.FromImageDigest}}", imageID).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect image: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// PushOptions describes how to push built images to a registry
+type PushOptions struct {
+	Username  string
+	Password  string
+	TLSVerify bool
+	Output    io.Writer // where buildah output is written (default stdout)
+}
+
+// Push pushes every tag to its registry and returns the manifest digest
+// reported by the registry
+func Push(ctx context.Context, tags []string, opts PushOptions) (string, error) {
+	if !Available() {
+		return "", fmt.Errorf("buildah not found in PATH")
+	}
+
+	digestFile, err := os.CreateTemp("", "qv-digest-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create digest file: %w", err)
+	}
+	digestFile.Close()
+	defer os.Remove(digestFile.Name())
+
+	// Credentials go through an auth file so the password is not on argv
+	var authFile string
+	if opts.Username != "" {
+		authFile, err = writeAuthFile(tags, opts.Username, opts.Password)
+		if err != nil {
+			return "", err
+		}
+		defer os.Remove(authFile)
+	}
+
+	for _, tag := range tags {
+		args := []string{"push", "--digestfile", digestFile.Name(), fmt.Sprintf("--tls-verify=%t", opts.TLSVerify)}
+		if authFile != "" {
+			args = append(args, "--authfile", authFile)
+		}
+		args = append(args, tag, "docker://"+tag)
+
+		out := outputOrStdout(opts.Output)
+		fmt.Fprintf(out, "Pushing %s...\n", tag)
+		cmd := exec.CommandContext(ctx, "buildah", args...)
+		cmd.Stdout = out
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return "", fmt.Errorf("failed to push %s: %w", tag, err)
+		}
+	}
+
+	digest, err := os.ReadFile(digestFile.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read pushed digest: %w", err)
+	}
+	return strings.TrimSpace(string(digest)), nil
+}
+
+// writeAuthFile writes the credentials for the registries of tags to a
+// temporary containers-auth.json file readable only by the current user
+// and returns its path
+func writeAuthFile(tags []string, username, password string) (string, error) {
+	auth := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	auths := make(map[string]map[string]string)
+	for _, tag := range tags {
+		auths[registryHost(tag)] = map[string]string{"auth": auth}
+	}
+	data, err := json.Marshal(map[string]any{"auths": auths})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode registry credentials: %w", err)
+	}
+
+	// CreateTemp creates the file with mode 0600
+	f, err := os.CreateTemp("", "qv-auth-*.json")
+	if err != nil {
+		return "", fmt.Errorf("failed to create auth file: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write auth file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write auth file: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// registryHost returns the registry of an image reference, which is
+// docker.io unless the first path component looks like a host
+func registryHost(ref string) string {
+	host, _, found := strings.Cut(ref, "/")
+	if !found || (!strings.ContainsAny(host, ".:") && host != "localhost") {
+		return "docker.io"
+	}
+	return host
+}
+
+// ImageName joins a registry host and image name
+func ImageName(registry, image string) string {
+	if registry == "" {
+		return image
+	}
+	return strings.TrimSuffix(registry, "/") + "/" + strings.TrimPrefix(image, "/")
+}
+
+// outputOrStdout returns w, or stdout if w is nil
+func outputOrStdout(w io.Writer) io.Writer {
+	if w == nil {
+		return os.Stdout
+	}
+	return w
+}
@@ -0,0 +1,211 @@
+// Package bump rewrites the version recorded in manifest files such as
+// VERSION, package.json or Chart.yaml. Only the version text is replaced,
+// so the rest of each file keeps its formatting.
+package bump
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Supported file formats
+const (
+	Plain     = "plain"     // the whole file is the version, e.g. VERSION
+	JSON      = "json"      // the first "version" key, e.g. package.json
+	Chart     = "chart"     // version and appVersion in a Helm Chart.yaml
+	Go        = "go"        // a Go const Version = "..." declaration
+	Pyproject = "pyproject" // version in [project] or [tool.poetry]
+	Regex     = "regex"     // the first capture group of a custom pattern
+)
+
+// Formats lists the supported file formats
+var Formats = []string{Plain, JSON, Chart, Go, Pyproject, Regex}
+
+// File is a file whose version is kept in sync with the released version
+type File struct {
+	Path    string
+	Format  string // one of Formats; detected from the file name if empty
+	Pattern string // regular expression with one capture group, for Regex
+}
+
+// A leading 'v' is kept outside the capture groups so it is preserved
+var (
+	plainPattern       = regexp.MustCompile(`\A\s*v?([^\s]+)`)
+	jsonPattern        = regexp.MustCompile(`"version"\s*:\s*"v?([^"]*)"`)
+	chartPattern       = regexp.MustCompile(`(?m)^version:[ \t]*["']?v?([^"'\s#]+)`)
+	chartAppPattern    = regexp.MustCompile(`(?m)^appVersion:[ \t]*["']?v?([^"'\s#]+)`)
+	goPattern          = regexp.MustCompile(`(?m)^\s*(?:const\s+)?Version(?:\s+string)?\s*=\s*"v?([^"]*)"`)
+	pyprojectPattern   = regexp.MustCompile(`(?m)^version\s*=\s*["']v?([^"']*)["']`)
+	tomlSectionPattern = regexp.MustCompile(`(?m)^\s*\[([^\]]+)\]`)
+)
+
+// DetectFormat returns the format for a file path, or an error if it
+// cannot be detected from the file name
+func DetectFormat(filePath string) (string, error) {
+	name := path.Base(filePath)
+	switch {
+	case name == "VERSION" || strings.HasSuffix(name, ".version"):
+		return Plain, nil
+	case strings.HasSuffix(name, ".json"):
+		return JSON, nil
+	case name == "Chart.yaml":
+		return Chart, nil
+	case strings.HasSuffix(name, ".go"):
+		return Go, nil
+	case name == "pyproject.toml":
+		return Pyproject, nil
+	default:
+		return "", fmt.Errorf("cannot detect the format of %s; set format or pattern in bump_files", filePath)
+	}
+}
+
+// Validate checks the file's format and pattern and fills in a detected
+// format
+func (f *File) Validate() error {
+	if f.Path == "" {
+		return fmt.Errorf("bump_files entry without a path")
+	}
+
+	if f.Format == "" {
+		if f.Pattern != "" {
+			f.Format = Regex
+		} else {
+			format, err := DetectFormat(f.Path)
+			if err != nil {
+				return err
+			}
+			f.Format = format
+		}
+	}
+
+	switch f.Format {
+	case Plain, JSON, Chart, Go, Pyproject:
+		return nil
+	case Regex:
+		if f.Pattern == "" {
+			return fmt.Errorf("%s: the regex format needs a pattern", f.Path)
+		}
+		re, err := regexp.Compile(f.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s: invalid pattern: %w", f.Path, err)
+		}
+		if re.NumSubexp() != 1 {
+			return fmt.Errorf("%s: pattern must have exactly one capture group around the version", f.Path)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%s: unknown format %q (must be one of: %s)", f.Path, f.Format, strings.Join(Formats, ", "))
+	}
+}
+
+// Versions returns every version found in content
+func (f *File) Versions(content []byte) ([]string, error) {
+	spans, err := f.spans(content)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, len(spans))
+	for i, s := range spans {
+		versions[i] = string(content[s[0]:s[1]])
+	}
+	return versions, nil
+}
+
+// Apply returns content with every version replaced by version
+func (f *File) Apply(content []byte, version string) ([]byte, error) {
+	spans, err := f.spans(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, s := range spans {
+		b.Write(content[last:s[0]])
+		b.WriteString(version)
+		last = s[1]
+	}
+	b.Write(content[last:])
+	return []byte(b.String()), nil
+}
+
+// spans returns the start and end offsets of each version in content
+func (f *File) spans(content []byte) ([][2]int, error) {
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+
+	var spans [][2]int
+	switch f.Format {
+	case Plain:
+		spans = firstGroup(plainPattern, content)
+	case JSON:
+		spans = firstGroup(jsonPattern, content)
+	case Chart:
+		spans = append(firstGroup(chartPattern, content), firstGroup(chartAppPattern, content)...)
+	case Go:
+		spans = firstGroup(goPattern, content)
+	case Pyproject:
+		spans = pyprojectSpans(content)
+	case Regex:
+		spans = allGroups(regexp.MustCompile(f.Pattern), content)
+	}
+
+	if len(spans) == 0 {
+		return nil, fmt.Errorf("no version found in %s", f.Path)
+	}
+
+	// Apply rewrites the spans in file order, e.g. appVersion may come
+	// before version in a Chart.yaml
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+	for i := 1; i < len(spans); i++ {
+		if spans[i][0] < spans[i-1][1] {
+			return nil, fmt.Errorf("overlapping versions found in %s", f.Path)
+		}
+	}
+	return spans, nil
+}
+
+// firstGroup returns the span of the first capture group of the first match
+func firstGroup(re *regexp.Regexp, content []byte) [][2]int {
+	loc := re.FindSubmatchIndex(content)
+	if loc == nil || loc[2] < 0 {
+		return nil
+	}
+	return [][2]int{{loc[2], loc[3]}}
+}
+
+// allGroups returns the spans of the first capture group of every match
+func allGroups(re *regexp.Regexp, content []byte) [][2]int {
+	var spans [][2]int
+	for _, loc := range re.FindAllSubmatchIndex(content, -1) {
+		if loc[2] >= 0 {
+			spans = append(spans, [2]int{loc[2], loc[3]})
+		}
+	}
+	return spans
+}
+
+// pyprojectSpans returns the span of the version key in the [project] or
+// [tool.poetry] table, ignoring version keys of other tables
+func pyprojectSpans(content []byte) [][2]int {
+	sections := tomlSectionPattern.FindAllSubmatchIndex(content, -1)
+
+	for _, loc := range pyprojectPattern.FindAllSubmatchIndex(content, -1) {
+		table := ""
+		for _, s := range sections {
+			if s[0] > loc[0] {
+				break
+			}
+			table = strings.TrimSpace(string(content[s[2]:s[3]]))
+		}
+		if table == "project" || table == "tool.poetry" {
+			return [][2]int{{loc[2], loc[3]}}
+		}
+	}
+	return nil
+}
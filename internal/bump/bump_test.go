@@ -0,0 +1,151 @@
+package bump
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    File
+		content string
+		want    string
+	}{
+		{
+			name:    "plain",
+			file:    File{Path: "VERSION"},
+			content: "1.2.3\n",
+			want:    "1.3.0\n",
+		},
+		{
+			name:    "plain keeps v prefix",
+			file:    File{Path: "app.version"},
+			content: "v1.2.3",
+			want:    "v1.3.0",
+		},
+		{
+			name:    "package.json",
+			file:    File{Path: "package.json"},
+			content: "{\n  \"name\": \"app\",\n  \"version\": \"1.2.3\",\n  \"dependencies\": {\n    \"x\": \"^2.0.0\"\n  }\n}\n",
+			want:    "{\n  \"name\": \"app\",\n  \"version\": \"1.3.0\",\n  \"dependencies\": {\n    \"x\": \"^2.0.0\"\n  }\n}\n",
+		},
+		{
+			name:    "chart version first",
+			file:    File{Path: "charts/app/Chart.yaml"},
+			content: "apiVersion: v2\nname: x\nversion: 0.1.0\nappVersion: \"1.0.0\"\n",
+			want:    "apiVersion: v2\nname: x\nversion: 1.3.0\nappVersion: \"1.3.0\"\n",
+		},
+		{
+			name:    "chart appVersion first",
+			file:    File{Path: "Chart.yaml"},
+			content: "apiVersion: v2\nappVersion: \"1.0.0\"\nname: x\nversion: 0.1.0\n",
+			want:    "apiVersion: v2\nappVersion: \"1.3.0\"\nname: x\nversion: 1.3.0\n",
+		},
+		{
+			name:    "go const",
+			file:    File{Path: "internal/version.go"},
+			content: "package version\n\n// Version is the release\nconst Version = \"v1.2.3\"\n",
+			want:    "package version\n\n// Version is the release\nconst Version = \"v1.3.0\"\n",
+		},
+		{
+			name:    "pyproject project table",
+			file:    File{Path: "pyproject.toml"},
+			content: "[build-system]\nversion = \"9.9.9\"\n\n[project]\nname = \"app\"\nversion = \"1.2.3\"\n",
+			want:    "[build-system]\nversion = \"9.9.9\"\n\n[project]\nname = \"app\"\nversion = \"1.3.0\"\n",
+		},
+		{
+			name:    "pyproject poetry table",
+			file:    File{Path: "pyproject.toml"},
+			content: "[tool.black]\nversion = '1'\n\n[tool.poetry]\nversion = '1.2.3'\n",
+			want:    "[tool.black]\nversion = '1'\n\n[tool.poetry]\nversion = '1.3.0'\n",
+		},
+		{
+			name:    "regex every match",
+			file:    File{Path: "deploy.env", Pattern: `APP_VERSION=(\S+)`},
+			content: "APP_VERSION=1.2.3\nOTHER=1\nAPP_VERSION=1.2.3\n",
+			want:    "APP_VERSION=1.3.0\nOTHER=1\nAPP_VERSION=1.3.0\n",
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.file.Apply([]byte(tt.content), "1.3.0")
+		if err != nil {
+			t.Errorf("%s: Apply returned error: %v", tt.name, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: Apply = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestVersions(t *testing.T) {
+	f := File{Path: "Chart.yaml"}
+	got, err := f.Versions([]byte("appVersion: v2.0.0\nversion: 0.4.1 # chart\n"))
+	if err != nil {
+		t.Fatalf("Versions returned error: %v", err)
+	}
+	if strings.Join(got, ",") != "2.0.0,0.4.1" {
+		t.Errorf("Versions = %v, want [2.0.0 0.4.1] in file order", got)
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    File
+		content string
+		wantErr string
+	}{
+		{
+			name:    "regex without match",
+			file:    File{Path: "deploy.env", Pattern: `APP_VERSION=(\S+)`},
+			content: "OTHER=1\n",
+			wantErr: "no version found",
+		},
+		{
+			name:    "regex without capture group",
+			file:    File{Path: "deploy.env", Pattern: `APP_VERSION=\S+`},
+			content: "APP_VERSION=1.2.3\n",
+			wantErr: "exactly one capture group",
+		},
+		{
+			name:    "regex with two capture groups",
+			file:    File{Path: "deploy.env", Pattern: `(APP)_VERSION=(\S+)`},
+			content: "APP_VERSION=1.2.3\n",
+			wantErr: "exactly one capture group",
+		},
+		{
+			name:    "pyproject version outside the project tables",
+			file:    File{Path: "pyproject.toml"},
+			content: "[tool.black]\nversion = \"1.2.3\"\n",
+			wantErr: "no version found",
+		},
+		{
+			name:    "json without version",
+			file:    File{Path: "package.json"},
+			content: "{\"name\": \"app\"}\n",
+			wantErr: "no version found",
+		},
+		{
+			name:    "undetectable format",
+			file:    File{Path: "settings.ini"},
+			content: "version=1.2.3\n",
+			wantErr: "cannot detect the format",
+		},
+		{
+			name:    "unknown format",
+			file:    File{Path: "VERSION", Format: "xml"},
+			content: "1.2.3\n",
+			wantErr: "unknown format",
+		},
+	}
+
+	for _, tt := range tests {
+		_, err := tt.file.Apply([]byte(tt.content), "1.3.0")
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: Apply error = %v, want one containing %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
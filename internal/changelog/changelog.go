@@ -0,0 +1,220 @@
+package changelog
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/excircle/quik-version/internal/conventional"
+	"github.com/excircle/quik-version/internal/version"
+)
+
+// DefaultPath is the default location of the changelog file
+const DefaultPath = "CHANGELOG.md"
+
+const header = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/),
+and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
+`
+
+// Change is a single line in a changelog section
+type Change struct {
+	SHA     string
+	Scope   string
+	Subject string
+}
+
+// Entry holds the grouped changes for one released version
+type Entry struct {
+	Version  string
+	Date     string
+	Breaking []Change
+	Features []Change
+	Fixes    []Change
+}
+
+// Commit is a raw commit to be grouped into an entry
+type Commit struct {
+	SHA     string
+	Message string
+}
+
+// NewEntry groups Conventional Commits into Breaking, Features and Fixes.
+// Commits that are not conventional or not releasable are ignored.
+func NewEntry(version, date string, commits []Commit) Entry {
+	entry := Entry{Version: version, Date: date}
+
+	for _, c := range commits {
+		parsed, err := conventional.Parse(c.Message)
+		if err != nil {
+			continue
+		}
+
+		change := Change{SHA: c.SHA, Scope: parsed.Scope, Subject: parsed.Subject}
+		switch {
+		case parsed.Breaking:
+			entry.Breaking = append(entry.Breaking, change)
+		case parsed.Type == "feat":
+			entry.Features = append(entry.Features, change)
+		case parsed.Type == "fix" || parsed.Type == "perf":
+			entry.Fixes = append(entry.Fixes, change)
+		}
+	}
+
+	return entry
+}
+
+// IsEmpty reports whether the entry has no notable changes
+func (e Entry) IsEmpty() bool {
+	return len(e.Breaking) == 0 && len(e.Features) == 0 && len(e.Fixes) == 0
+}
+
+// Markdown renders the entry as a Keep a Changelog section
+func (e Entry) Markdown() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "## [%s] - %s\n", e.Version, e.Date)
+
+	if e.IsEmpty() {
+		b.WriteString("\nNo notable changes.\n")
+		return b.String()
+	}
+
+	writeSection(&b, "Breaking", e.Breaking)
+	writeSection(&b, "Features", e.Features)
+	writeSection(&b, "Fixes", e.Fixes)
+
+	return b.String()
+}
+
+func writeSection(b *strings.Builder, title string, changes []Change) {
+	if len(changes) == 0 {
+		return
+	}
+
+	fmt.Fprintf(b, "\n### %s\n\n", title)
+	for _, c := range changes {
+		b.WriteString("- ")
+		if c.Scope != "" {
+			fmt.Fprintf(b, "**%s:** ", c.Scope)
+		}
+		b.WriteString(c.Subject)
+		if c.SHA != "" {
+			fmt.Fprintf(b, " (%s)", shortSHA(c.SHA))
+		}
+		b.WriteString("\n")
+	}
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// Render renders a complete changelog from entries, newest first
+func Render(entries []Entry) string {
+	var b strings.Builder
+	b.WriteString(header)
+	for _, e := range entries {
+		b.WriteString("\n")
+		b.WriteString(e.Markdown())
+	}
+	return b.String()
+}
+
+// Write writes a complete changelog to path, replacing any existing file
+func Write(path string, entries []Entry) error {
+	if err := os.WriteFile(path, []byte(Render(entries)), 0644); err != nil {
+		return fmt.Errorf("failed to write changelog: %w", err)
+	}
+	return nil
+}
+
+// Prepend inserts an entry into the changelog at path, above the entries of
+// lower versions, creating the file if needed. An existing section for the
+// same version is replaced.
+func Prepend(path string, entry Entry) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Write(path, []Entry{entry})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read changelog: %w", err)
+	}
+
+	updated := Insert(string(data), entry)
+	if err := os.WriteFile(path, []byte(updated), 0644); err != nil {
+		return fmt.Errorf("failed to write changelog: %w", err)
+	}
+	return nil
+}
+
+// Insert returns content with entry placed among the version sections by
+// SemVer precedence, newest first, replacing an existing section for the
+// same version. An [Unreleased] section stays on top.
+func Insert(content string, entry Entry) string {
+	content = removeSection(content, entry.Version)
+
+	idx := insertionPoint(content, entry.Version)
+	if idx < 0 {
+		if strings.TrimSpace(content) == "" {
+			content = header
+		}
+		return strings.TrimRight(content, "\n") + "\n\n" + entry.Markdown()
+	}
+
+	return content[:idx+1] + entry.Markdown() + "\n" + content[idx+1:]
+}
+
+// insertionPoint returns the index of the newline before the first version
+// section heading in content that is lower than v, skipping [Unreleased],
+// or -1 to append. Headings that are not versions are skipped too; if v
+// is not a version, the entry goes before the first version section.
+func insertionPoint(content, v string) int {
+	parsed, err := version.Parse(v)
+
+	offset := 0
+	for {
+		idx := strings.Index(content[offset:], "\n## [")
+		if idx < 0 {
+			return -1
+		}
+		idx += offset
+		offset = idx + 1
+
+		heading := content[idx+len("\n## ["):]
+		end := strings.Index(heading, "]")
+		if end < 0 || strings.EqualFold(heading[:end], "unreleased") {
+			continue
+		}
+		if err != nil {
+			return idx
+		}
+		existing, parseErr := version.Parse(heading[:end])
+		if parseErr != nil {
+			continue
+		}
+		if parsed.Compare(existing) > 0 {
+			return idx
+		}
+	}
+}
+
+// removeSection removes the section for version from content if present
+func removeSection(content, version string) string {
+	start := strings.Index(content, "## ["+version+"]")
+	if start < 0 {
+		return content
+	}
+
+	end := strings.Index(content[start+1:], "\n## [")
+	if end < 0 {
+		return strings.TrimRight(content[:start], "\n") + "\n"
+	}
+	return content[:start] + content[start+1+end+1:]
+}
@@ -0,0 +1,83 @@
+package changelog
+
+import (
+	"strings"
+	"testing"
+)
+
+const unreleased = "## [Unreleased]\n\n- work in progress\n"
+
+// section renders the section of version as Insert writes it
+func section(version string) string {
+	return Entry{Version: version, Date: "2026-01-01"}.Markdown()
+}
+
+// changelog joins sections below the header like Render
+func changelog(sections ...string) string {
+	return header + "\n" + strings.Join(sections, "\n")
+}
+
+func TestInsert(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		version string
+		want    string
+	}{
+		{
+			name:    "empty file",
+			content: "",
+			version: "1.0.0",
+			want:    changelog(section("1.0.0")),
+		},
+		{
+			name:    "only unreleased",
+			content: changelog(unreleased),
+			version: "1.0.0",
+			want:    changelog(unreleased, section("1.0.0")),
+		},
+		{
+			name:    "newest on top below unreleased",
+			content: changelog(unreleased, section("1.0.0")),
+			version: "1.1.0",
+			want:    changelog(unreleased, section("1.1.0"), section("1.0.0")),
+		},
+		{
+			name:    "patch of an older line",
+			content: changelog(section("2.0.0"), section("1.1.0"), section("1.0.0")),
+			version: "1.1.1",
+			want:    changelog(section("2.0.0"), section("1.1.1"), section("1.1.0"), section("1.0.0")),
+		},
+		{
+			name:    "pre-release below its release",
+			content: changelog(section("2.0.0"), section("1.0.0")),
+			version: "2.0.0-rc.1",
+			want:    changelog(section("2.0.0"), section("2.0.0-rc.1"), section("1.0.0")),
+		},
+		{
+			name:    "lower than every section",
+			content: changelog(section("1.1.0"), section("1.0.0")),
+			version: "0.9.0",
+			want:    changelog(section("1.1.0"), section("1.0.0"), section("0.9.0")),
+		},
+		{
+			name:    "existing version is replaced",
+			content: changelog(section("1.1.0"), "## [1.0.0] - 2025-12-01\n\n- old notes\n", section("0.9.0")),
+			version: "1.0.0",
+			want:    changelog(section("1.1.0"), section("1.0.0"), section("0.9.0")),
+		},
+		{
+			name:    "existing latest version is replaced",
+			content: changelog(unreleased, "## [1.1.0] - 2025-12-01\n\n- old notes\n", section("1.0.0")),
+			version: "1.1.0",
+			want:    changelog(unreleased, section("1.1.0"), section("1.0.0")),
+		},
+	}
+
+	for _, tt := range tests {
+		entry := Entry{Version: tt.version, Date: "2026-01-01"}
+		if got := Insert(tt.content, entry); got != tt.want {
+			t.Errorf("%s: Insert =\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+}
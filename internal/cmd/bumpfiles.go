@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/excircle/quik-version/internal/bump"
+	"github.com/excircle/quik-version/internal/config"
+	"github.com/excircle/quik-version/internal/db"
+)
+
+var bumpCheck bool
+
+var bumpFilesCmd = &cobra.Command{
+	Use:   "bump-files",
+	Short: "Write the planned version into the files listed in bump_files",
+	Long: `Bump-files rewrites the version in every file listed in bump_files in
+quik.conf to the next version in plan.yaml. Only the version text is
+replaced, so the rest of each file keeps its formatting.
+
+Supported formats (detected from the file name unless format is set):
+- plain: the whole file is the version (VERSION)
+- json: the first "version" key (package.json)
+- chart: version and appVersion (Chart.yaml)
+- go: a const Version = "..." declaration (*.go)
+- pyproject: version in [project] or [tool.poetry] (pyproject.toml)
+- regex: the capture group of pattern
+
+'qv deploy' commits the rewritten files on top of the planned commit and
+tags that bump commit, so the tag includes the new version. 'qv pr
+--create-branch' and 'qv release-pr' commit them to the release branch
+instead, and deploy then tags the planned commit as is. With the git
+backend, run it before 'qv deploy', commit the result and plan that
+commit again.
+
+Use --check to fail if any file disagrees with the latest version in
+qv.db, e.g. in CI.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if bumpCheck {
+			return checkBumpFiles()
+		}
+
+		// Check if plan.yaml exists
+		if _, err := os.Stat(planFileName); os.IsNotExist(err) {
+			return fmt.Errorf("plan.yaml not found. Run 'qv plan' first")
+		}
+
+		planData, err := os.ReadFile(planFileName)
+		if err != nil {
+			return fmt.Errorf("failed to read plan file: %w", err)
+		}
+
+		var plan PlanFile
+		if err := yaml.Unmarshal(planData, &plan); err != nil {
+			return fmt.Errorf("failed to parse plan file: %w", err)
+		}
+
+		// The plan decides which component is bumped
+		if componentName != "" && componentName != plan.Component {
+			return fmt.Errorf("plan.yaml was created for component %q, not %q", plan.Component, componentName)
+		}
+
+		files, err := bumpFiles(plan.Component)
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			return fmt.Errorf("no bump_files configured in quik.conf")
+		}
+
+		return writeBumpFiles(files, plan.NextVersion)
+	},
+}
+
+// bumpFiles returns the validated bump_files of a component
+func bumpFiles(componentName string) ([]bump.File, error) {
+	var files []bump.File
+	for _, c := range config.GetBumpFiles(componentName) {
+		f := bump.File{Path: c.Path, Format: c.Format, Pattern: c.Pattern}
+		if err := f.Validate(); err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// writeBumpFiles rewrites the version in files in the working tree
+func writeBumpFiles(files []bump.File, version string) error {
+	for _, f := range files {
+		content, err := os.ReadFile(f.Path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", f.Path, err)
+		}
+
+		updated, err := f.Apply(content, version)
+		if err != nil {
+			return err
+		}
+		if string(updated) == string(content) {
+			printf("%s is already at %s\n", f.Path, version)
+			continue
+		}
+
+		if err := os.WriteFile(f.Path, updated, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", f.Path, err)
+		}
+		printf("Updated %s to %s\n", f.Path, version)
+	}
+	return nil
+}
+
+// checkBumpFiles fails if a bump file disagrees with the latest version
+// recorded in qv.db
+func checkBumpFiles() error {
+	// Check if database exists
+	if !db.Exists() {
+		return fmt.Errorf("database not found. Run 'qv init' first")
+	}
+
+	gitURL := config.GetGitURL()
+	if gitURL == "" {
+		return fmt.Errorf("git_url not configured. Run 'qv init' first")
+	}
+
+	comp, err := resolveComponent()
+	if err != nil {
+		return err
+	}
+
+	files, err := bumpFiles(comp.Name)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no bump_files configured in quik.conf")
+	}
+
+	database, err := db.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	latest, err := database.GetLatestVersion(gitURL, comp.Name)
+	if err != nil {
+		return fmt.Errorf("failed to get latest version: %w", err)
+	}
+	if latest == nil {
+		return fmt.Errorf("no versions recorded for %s", comp.Label())
+	}
+
+	mismatched := 0
+	for _, f := range files {
+		content, err := os.ReadFile(f.Path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", f.Path, err)
+		}
+		versions, err := f.Versions(content)
+		if err != nil {
+			return err
+		}
+
+		for _, v := range versions {
+			if v != latest.Version {
+				fmt.Printf("✗ %s: %s, expected %s\n", f.Path, v, latest.Version)
+				mismatched++
+			} else {
+				fmt.Printf("✓ %s: %s\n", f.Path, v)
+			}
+		}
+	}
+
+	if mismatched > 0 {
+		return fmt.Errorf("%d versions in bump_files disagree with v%s in qv.db", mismatched, latest.Version)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(bumpFilesCmd)
+	bumpFilesCmd.Flags().BoolVar(&bumpCheck, "check", false, "fail if any file disagrees with the latest version in qv.db")
+	addComponentFlag(bumpFilesCmd)
+}
@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/excircle/quik-version/internal/changelog"
+	"github.com/excircle/quik-version/internal/component"
+	"github.com/excircle/quik-version/internal/config"
+	"github.com/excircle/quik-version/internal/db"
+	"github.com/excircle/quik-version/internal/forge"
+	"github.com/excircle/quik-version/internal/version"
+)
+
+var (
+	changelogAll  bool
+	changelogFile string
+)
+
+var changelogCmd = &cobra.Command{
+	Use:   "changelog",
+	Short: "Generate CHANGELOG.md from commit history",
+	Long: `Changelog groups Conventional Commits between released versions
+into Breaking, Features and Fixes sections using the Keep a Changelog format.
+
+By default, the section for the latest version in qv.db is written to
+the changelog, above the sections of lower versions and below
+[Unreleased]. Use --all to regenerate the whole file from every version
+in qv.db.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		// Check if database exists
+		if !db.Exists() {
+			return fmt.Errorf("database not found. Run 'qv init' first")
+		}
+
+		// Get git URL from config
+		gitURL := config.GetGitURL()
+		if gitURL == "" {
+			return fmt.Errorf("git_url not configured. Run 'qv init' first")
+		}
+
+		// Open database
+		database, err := db.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer database.Close()
+
+		// Resolve the component whose changelog is generated
+		comp, err := resolveComponent()
+		if err != nil {
+			return err
+		}
+
+		history, err := database.GetVersionHistory(gitURL, comp.Name)
+		if err != nil {
+			return fmt.Errorf("failed to get version history: %w", err)
+		}
+		if len(history) == 0 {
+			return fmt.Errorf("no versions recorded yet")
+		}
+
+		// Create forge client
+		client, err := newForge(ctx, gitURL)
+		if err != nil {
+			return err
+		}
+
+		path := changelogPath(comp)
+
+		if !changelogAll {
+			latest := len(history) - 1
+			previous, err := changelogPrevious(ctx, client, history, latest)
+			if err != nil {
+				return err
+			}
+
+			entry, err := buildChangelogEntry(ctx, client, comp, previous, &history[latest])
+			if err != nil {
+				return err
+			}
+
+			if err := changelog.Prepend(path, entry); err != nil {
+				return err
+			}
+			fmt.Printf("Updated %s with v%s\n", path, entry.Version)
+			return nil
+		}
+
+		// Regenerate every version, newest first
+		entries := make([]changelog.Entry, 0, len(history))
+		for i := len(history) - 1; i >= 0; i-- {
+			previous, err := changelogPrevious(ctx, client, history, i)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Collecting changes for v%s...\n", history[i].Version)
+			entry, err := buildChangelogEntry(ctx, client, comp, previous, &history[i])
+			if err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+		}
+
+		if err := changelog.Write(path, entries); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %s with %d versions\n", path, len(entries))
+
+		return nil
+	},
+}
+
+// changelogPath returns the changelog file to write, preferring --file
+// over the configured path. Components with a path keep their changelog
+// in that directory.
+func changelogPath(comp *component.Component) string {
+	if changelogFile != "" {
+		return changelogFile
+	}
+	if path := config.GetChangelogPath(); path != "" {
+		return path
+	}
+	if comp.Path != "" {
+		return filepath.Join(comp.Path, changelog.DefaultPath)
+	}
+	return changelog.DefaultPath
+}
+
+// changelogPrevious returns the version the changes of history[i] are
+// counted from: the next lower version in the same release line, or else
+// the next lower version tagged on an ancestor commit. Versions released
+// on other branches, such as 1.8.4 on release/1.8 for 1.9.0, are skipped.
+func changelogPrevious(ctx context.Context, client forge.Forge, history []db.Version, i int) (*db.Version, error) {
+	current, err := version.Parse(history[i].Version)
+	if err != nil {
+		return nil, err
+	}
+
+	for j := i - 1; j >= 0; j-- {
+		candidate, err := version.Parse(history[j].Version)
+		if err != nil {
+			continue
+		}
+		if current.Line() == candidate.Line() {
+			return &history[j], nil
+		}
+
+		// The candidate is an ancestor if the current commit contains
+		// every commit reachable from it
+		commits, err := client.CompareCommits(ctx, history[i].GitSHA, history[j].GitSHA)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare %s with %s: %w", history[i].TagName, history[j].TagName, err)
+		}
+		if len(commits) == 0 {
+			return &history[j], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// buildChangelogEntry collects the commits between the previous version and
+// current that touched the component and groups them into a changelog entry
+func buildChangelogEntry(ctx context.Context, client forge.Forge, comp *component.Component, previous, current *db.Version) (changelog.Entry, error) {
+	var commits []forge.Commit
+	var err error
+	if previous == nil {
+		commits, err = client.ListCommits(ctx, current.GitSHA)
+	} else {
+		commits, err = client.CompareCommits(ctx, previous.GitSHA, current.GitSHA)
+	}
+	if err != nil {
+		return changelog.Entry{}, fmt.Errorf("failed to list commits for v%s: %w", current.Version, err)
+	}
+
+	commits, err = filterComponentCommits(ctx, client, comp, commits)
+	if err != nil {
+		return changelog.Entry{}, err
+	}
+
+	return changelog.NewEntry(current.Version, releaseDate(current.CreatedAt), toChangelogCommits(commits)), nil
+}
+
+// toChangelogCommits converts forge commits for the changelog package
+func toChangelogCommits(commits []forge.Commit) []changelog.Commit {
+	result := make([]changelog.Commit, len(commits))
+	for i, c := range commits {
+		result[i] = changelog.Commit{SHA: c.SHA, Message: c.Message}
+	}
+	return result
+}
+
+// releaseDate returns the YYYY-MM-DD portion of a database timestamp, or
+// today's date for a version that has not been stored yet
+func releaseDate(createdAt string) string {
+	if createdAt == "" {
+		return time.Now().Format("2006-01-02")
+	}
+	if len(createdAt) >= 10 {
+		return createdAt[:10]
+	}
+	return createdAt
+}
+
+func init() {
+	rootCmd.AddCommand(changelogCmd)
+	changelogCmd.Flags().BoolVar(&changelogAll, "all", false, "regenerate the changelog for every recorded version")
+	addComponentFlag(changelogCmd)
+	changelogCmd.Flags().StringVar(&changelogFile, "file", "", "changelog file to write (default is changelog.path or CHANGELOG.md)")
+}
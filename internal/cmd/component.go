@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/excircle/quik-version/internal/component"
+	"github.com/excircle/quik-version/internal/forge"
+)
+
+var componentName string
+
+// addComponentFlag registers --component on a command
+func addComponentFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&componentName, "component", "", "monorepo component to version (see components in quik.conf)")
+}
+
+// resolveComponent returns the component selected with --component, or the
+// default component for the whole repository
+func resolveComponent() (*component.Component, error) {
+	return component.Lookup(componentName)
+}
+
+// filterComponentCommits keeps only the commits that touched the
+// component's path. Components without a path keep every commit.
+func filterComponentCommits(ctx context.Context, client forge.Forge, comp *component.Component, commits []forge.Commit) ([]forge.Commit, error) {
+	if comp.Path == "" {
+		return commits, nil
+	}
+
+	var filtered []forge.Commit
+	for _, c := range commits {
+		files, err := client.CommitFiles(ctx, c.SHA)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get files for commit %s: %w", c.SHA, err)
+		}
+		if comp.Touches(files) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered, nil
+}
@@ -1,19 +1,48 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 
+	"github.com/excircle/quik-version/internal/build"
+	"github.com/excircle/quik-version/internal/changelog"
+	"github.com/excircle/quik-version/internal/component"
 	"github.com/excircle/quik-version/internal/config"
 	"github.com/excircle/quik-version/internal/db"
-	"github.com/excircle/quik-version/internal/github"
+	"github.com/excircle/quik-version/internal/forge"
+	"github.com/excircle/quik-version/internal/version"
 )
 
-var targetBranch string
+var (
+	targetBranch   string
+	resumeDeploy   bool
+	rollbackDeploy bool
+	forceDeploy    bool
+	fromMergedPR   bool
+)
+
+// Deploy steps, in the order they run. Each step is recorded in qv.db so a
+// failed deploy can be resumed or rolled back.
+const (
+	stepResolveCommit   = "resolve_commit"
+	stepBumpFiles       = "bump_files"
+	stepCreateTagObject = "create_tag_object"
+	stepCreateRef       = "create_ref"
+	stepBuild           = "build"
+	stepPush            = "push"
+	stepRecord          = "record"
+)
 
 var deployCmd = &cobra.Command{
 	Use:   "deploy",
@@ -22,98 +51,324 @@ var deployCmd = &cobra.Command{
 
 This command will:
 - Read plan.yaml (fail if missing)
-- Authenticate to GitHub
-- Create git tag with next_version on latest main commit
-- Push tag to GitHub
-- If build_management is enabled, trigger buildah container build
+- Authenticate to the forge (GitHub, GitLab or Gitea)
+- Refuse to deploy a stale plan: the plan's branch has moved past the
+  pinned commit, qv.db's versions changed since the plan was created, or
+  git_url differs from quik.conf (override with --force)
+- If bump_files are configured and do not hold next_version yet, commit
+  the rewritten files on top of the pinned commit, which must be the
+  head of the plan's branch
+- Create git tag with next_version on the commit pinned by the plan (or
+  the commit given with --sha or --ref, or the bump commit), named by the
+  component's tag_template for monorepo components
+- Push tag to the forge
+- If build_management is enabled, build the Containerfile with buildah
+  and tag the image with the version, major, major.minor and latest
+- If build.registry is set, push every image tag to the registry
 - Update qv.db with new version record
-- Delete plan.yaml after successful deploy`,
-	RunE: func(cmd *cobra.Command, args []string) error {
+- If changelog.enabled is set, add the release to CHANGELOG.md
+- If release.enabled is set, publish a release for the tag
+- Delete plan.yaml after successful deploy
+- Record who deployed, from which host and with what outcome in the
+  deployment history (see 'qv history'), including deploys refused by
+  the checks above
+
+Resolving the commit, committing the bump_files, creating the tag
+object, creating the tag ref, building, pushing and recording the
+version are recorded as steps in qv.db. If one fails, fix the cause and
+run 'qv deploy --resume' to continue from the failed step, or
+'qv deploy --rollback' to delete the created tag so the plan can be
+deployed again from scratch. A bump commit stays on the branch.
+
+With --from-merged-pr the plan is taken from the most recently merged
+release PR (see 'qv release-pr') instead of plan.yaml, and the PR's
+merge commit is tagged. Nothing is deployed if that version is already
+released.
+
+Use --output json or --output yaml to print the deployed version, tag,
+commit, image and release as a document on stdout; progress messages then
+go to stderr.`,
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		ctx := context.Background()
 
-		// Check if plan.yaml exists
-		if _, err := os.Stat(planFileName); os.IsNotExist(err) {
-			return fmt.Errorf("plan.yaml not found. Run 'qv plan' first")
+		// Get git URL from config
+		gitURL := config.GetGitURL()
+		if gitURL == "" {
+			return fmt.Errorf("git_url not configured. Run 'qv init' first")
 		}
 
-		// Read plan.yaml
-		planData, err := os.ReadFile(planFileName)
-		if err != nil {
-			return fmt.Errorf("failed to read plan file: %w", err)
+		if rollbackDeploy {
+			return rollbackDeployment(ctx, gitURL)
 		}
 
 		var plan PlanFile
-		if err := yaml.Unmarshal(planData, &plan); err != nil {
-			return fmt.Errorf("failed to parse plan file: %w", err)
+		var planData []byte
+		var client forge.Forge
+		if fromMergedPR {
+			if targetSHA != "" || targetRef != "" {
+				return fmt.Errorf("--sha and --ref cannot be combined with --from-merged-pr")
+			}
+			if _, err := component.Lookup(componentName); err != nil {
+				return err
+			}
+
+			client, err = newForge(ctx, gitURL)
+			if err != nil {
+				return err
+			}
+
+			// The merged release PR carries the plan
+			merged, pr, err := mergedReleasePlan(ctx, client, componentName)
+			if err != nil {
+				return err
+			}
+			released, err := isReleased(gitURL, merged)
+			if err != nil {
+				return err
+			}
+			if released {
+				printf("v%s from PR #%d is already released, nothing to deploy\n", merged.NextVersion, pr.Number)
+				return nil
+			}
+			printf("Using the plan from PR #%d (merged as %s)\n", pr.Number, merged.CommitSHA[:7])
+
+			plan = *merged
+			planData, err = yaml.Marshal(&plan)
+			if err != nil {
+				return fmt.Errorf("failed to marshal plan: %w", err)
+			}
+		} else {
+			// Check if plan.yaml exists
+			if _, err := os.Stat(planFileName); os.IsNotExist(err) {
+				return fmt.Errorf("plan.yaml not found. Run 'qv plan' first")
+			}
+
+			// Read plan.yaml
+			planData, err = os.ReadFile(planFileName)
+			if err != nil {
+				return fmt.Errorf("failed to read plan file: %w", err)
+			}
+
+			if err := yaml.Unmarshal(planData, &plan); err != nil {
+				return fmt.Errorf("failed to parse plan file: %w", err)
+			}
 		}
 
-		// Get git URL from config
-		gitURL := config.GetGitURL()
-		if gitURL == "" {
-			return fmt.Errorf("git_url not configured. Run 'qv init' first")
+		// The plan decides which component is deployed
+		if componentName != "" && componentName != plan.Component {
+			return fmt.Errorf("plan.yaml was created for component %q, not %q", plan.Component, componentName)
 		}
+		comp, err := component.Lookup(plan.Component)
+		if err != nil {
+			return err
+		}
+
+		// Create forge client
+		if client == nil {
+			client, err = newForge(ctx, gitURL)
+			if err != nil {
+				return err
+			}
+		}
+
+		printf("Deploying %s v%s to %s...\n", comp.Label(), plan.NextVersion, client.RepoPath())
+		printLine("---")
 
-		// Parse owner and repo
-		owner, repo, err := github.ParseRepoURL(gitURL)
+		// Open database
+		database, err := db.Open()
 		if err != nil {
-			return fmt.Errorf("failed to parse git URL: %w", err)
+			return fmt.Errorf("failed to open database: %w", err)
 		}
+		defer database.Close()
 
-		fmt.Printf("Deploying v%s to %s/%s...\n", plan.NextVersion, owner, repo)
-		fmt.Println("---")
+		// Deploy the branch the plan was made for
+		branch := plan.Branch
+		if branch == "" {
+			branch = "main"
+		}
+		if targetBranch != "" && targetBranch != branch {
+			return fmt.Errorf("plan.yaml was created for branch %q, not %q. Run 'qv plan --branch %s' first", branch, targetBranch, targetBranch)
+		}
 
-		// Create GitHub client
-		client, err := github.NewClient(ctx)
+		// The commit to tag: the plan's pinned commit, or --sha/--ref
+		ref, err := explicitTarget()
 		if err != nil {
-			return fmt.Errorf("failed to create GitHub client: %w", err)
+			return err
 		}
+		pinned := plan.CommitSHA
 
-		// Get latest commit SHA on target branch
-		fmt.Printf("Getting latest commit on '%s'...\n", targetBranch)
-		commitSHA, err := client.GetLatestCommitSHA(ctx, owner, repo, targetBranch)
+		// A resumed deploy carries on from its recorded steps instead
+		if resumeDeploy && ref != "" {
+			return fmt.Errorf("--sha and --ref cannot be combined with --resume")
+		}
+
+		// Start a new deployment, or pick up the failed one with --resume
+		tagName := comp.TagName(plan.NextVersion)
+		deployment, steps, err := startDeployment(ctx, database, client, gitURL, comp, plan.NextVersion, tagName, planData)
 		if err != nil {
-			return fmt.Errorf("failed to get latest commit: %w", err)
+			return err
 		}
-		fmt.Printf("Commit: %s\n", commitSHA[:7])
 
-		// Create tag
-		tagName := "v" + plan.NextVersion
-		tagMessage := fmt.Sprintf("Release %s", tagName)
+		// Record the outcome from the returned error, including failed checks
+		defer func() {
+			if finishErr := database.FinishDeployment(deployment.ID, err); finishErr != nil {
+				printf("Warning: %v\n", finishErr)
+			}
+		}()
 
-		fmt.Printf("Creating tag '%s'...\n", tagName)
-		if err := client.CreateTag(ctx, owner, repo, tagName, commitSHA, tagMessage); err != nil {
-			return fmt.Errorf("failed to create tag: %w", err)
+		// Check the plan unless a previous attempt already resolved the commit
+		if _, resolved := steps.completed(stepResolveCommit); !resolved {
+			if ref != "" {
+				sha, err := resolveTarget(ctx, client, ref, branch)
+				if err != nil {
+					return err
+				}
+				if pinned != "" && sha != pinned && !forceDeploy {
+					return fmt.Errorf("plan.yaml pinned commit %s, but %s is %s. Run 'qv plan' with the same --sha or --ref, or deploy anyway with --force", pinned[:7], ref, sha[:7])
+				}
+				pinned = sha
+			}
+
+			// A merged release PR pins its merge commit, not the branch head
+			if err := checkPlan(ctx, client, database, &plan, gitURL, branch, ref != "" || fromMergedPR); err != nil {
+				return err
+			}
+
+			if pinned != "" {
+				if err := checkDescendantVersions(ctx, client, database, gitURL, comp, plan.NextVersion, pinned); err != nil {
+					return err
+				}
+			}
 		}
 
-		// Check if build_management is enabled
-		if config.GetBuildManagement() {
-			fmt.Println("Build management is enabled (buildah integration not yet implemented)")
+		// Use the pinned commit, or the latest commit for older plans
+		commitSHA, err := steps.run(stepResolveCommit, func() (string, error) {
+			if pinned != "" {
+				return pinned, nil
+			}
+			printf("Getting latest commit on '%s'...\n", branch)
+			sha, err := client.GetLatestCommitSHA(ctx, branch)
+			if err != nil {
+				return "", fmt.Errorf("failed to get latest commit: %w", err)
+			}
+			return sha, nil
+		})
+		if err != nil {
+			return err
 		}
+		printf("Commit: %s\n", commitSHA[:7])
 
-		// Open database
-		database, err := db.Open()
+		// Commit the bump_files first so the tag includes them
+		commitSHA, err = bumpCommit(ctx, client, steps, comp, branch, commitSHA, plan.NextVersion, tagName)
 		if err != nil {
-			return fmt.Errorf("failed to open database: %w", err)
+			return err
 		}
-		defer database.Close()
 
-		// Insert new version record
+		// Create tag
+		if err := createTag(ctx, client, steps, tagName, commitSHA); err != nil {
+			return err
+		}
+
+		// Remember the previous release for the changelog
+		previousVersion, err := previousRelease(database, gitURL, comp, plan.Line)
+		if err != nil {
+			return err
+		}
+
+		// Build and push container image if build_management is enabled
+		var image *build.Result
+		var imageRecord *db.Image
+		if config.GetBuildManagement() {
+			image, imageRecord, err = buildImage(ctx, steps, gitURL, comp.Name, plan.NextVersion)
+			if err != nil {
+				return fmt.Errorf("tag %s was created, but the image build failed: %w", tagName, err)
+			}
+		}
+
+		// Record the new version and its image
 		incrementType := plan.IncrementType
 		newVersion := &db.Version{
 			Version:       plan.NextVersion,
 			TagName:       tagName,
 			GitSHA:        commitSHA,
 			GitURL:        gitURL,
+			Component:     comp.Name,
 			IncrementType: &incrementType,
 		}
+		if plan.Channel != "" {
+			channel := plan.Channel
+			newVersion.Channel = &channel
+		}
 
-		if err := database.InsertVersion(newVersion); err != nil {
-			return fmt.Errorf("failed to record version in database: %w", err)
+		_, err = steps.run(stepRecord, func() (string, error) {
+			if err := database.RecordVersion(newVersion, imageRecord); err != nil {
+				return "", fmt.Errorf("failed to record version in database: %w", err)
+			}
+			return plan.NextVersion, nil
+		})
+		if err != nil {
+			return err
+		}
+
+		// Generate release notes for the changelog and GitHub release
+		var notes *changelog.Entry
+		if config.GetChangelogEnabled() || config.GetReleaseEnabled() {
+			entry, err := buildChangelogEntry(ctx, client, comp, previousVersion, newVersion)
+			if err != nil {
+				printf("Warning: failed to generate release notes: %v\n", err)
+			} else {
+				notes = &entry
+			}
+		}
+
+		// Update changelog
+		if config.GetChangelogEnabled() && notes != nil {
+			if err := changelog.Prepend(changelogPath(comp), *notes); err != nil {
+				printf("Warning: %v\n", err)
+			} else {
+				printf("Updated %s\n", changelogPath(comp))
+			}
+		}
+
+		// Publish release
+		var release *forge.Release
+		if config.GetReleaseEnabled() {
+			release, err = publishRelease(ctx, client, tagName, plan.NextVersion, notes)
+			if err != nil {
+				printf("Warning: %v\n", err)
+			} else if err := database.SetRelease(gitURL, comp.Name, plan.NextVersion, release.ID, release.URL); err != nil {
+				printf("Warning: %v\n", err)
+			}
 		}
 
 		// Delete plan.yaml
-		if err := os.Remove(planFileName); err != nil {
-			fmt.Printf("Warning: failed to delete %s: %v\n", planFileName, err)
+		if !fromMergedPR {
+			if err := os.Remove(planFileName); err != nil {
+				printf("Warning: failed to delete %s: %v\n", planFileName, err)
+			}
+		}
+
+		result := DeployOutput{
+			DeploymentID: deployment.ID,
+			Component:    comp.Name,
+			Version:      plan.NextVersion,
+			Tag:          tagName,
+			CommitSHA:    commitSHA,
+			Changelog:    config.GetChangelogEnabled() && notes != nil,
+		}
+		if image != nil {
+			result.Image = &DeployImage{
+				ID:           image.ImageID,
+				Digest:       image.Digest,
+				PushedDigest: image.PushedDigest,
+				Tags:         image.Tags,
+			}
+		}
+		if release != nil {
+			result.ReleaseURL = release.URL
+		}
+		if machineOutput() {
+			return writeOutput(result)
 		}
 
 		fmt.Println()
@@ -122,12 +377,614 @@ This command will:
 		fmt.Printf("Version: v%s\n", plan.NextVersion)
 		fmt.Printf("Tag: %s\n", tagName)
 		fmt.Printf("Commit: %s\n", commitSHA)
+		if image != nil {
+			fmt.Printf("Image: %s\n", image.ImageID)
+			if image.PushedDigest != "" {
+				fmt.Printf("Pushed Digest: %s\n", image.PushedDigest)
+			}
+			for _, tag := range image.Tags {
+				fmt.Printf("  %s\n", tag)
+			}
+		}
+		if release != nil {
+			fmt.Printf("Release: %s\n", release.URL)
+		}
 
 		return nil
 	},
 }
 
+// checkPlan verifies that plan.yaml still describes the repository: its
+// git_url matches quik.conf, its branch (or --ref) has not moved past the
+// pinned commit, and qv.db's versions have not changed. The commit is not
+// checked when it was chosen explicitly on the command line. With --force
+// the problems are reported as warnings instead.
+func checkPlan(ctx context.Context, client forge.Forge, database *db.DB, plan *PlanFile, gitURL, branch string, explicit bool) error {
+	var problems []string
+
+	if plan.GitURL != gitURL {
+		problems = append(problems, fmt.Sprintf("plan.yaml was created for %s, but git_url is %s", plan.GitURL, gitURL))
+	}
+
+	switch {
+	case explicit || plan.CommitSHA == "":
+	case plan.Ref != "":
+		sha, err := resolveTarget(ctx, client, plan.Ref, branch)
+		if err != nil {
+			problems = append(problems, err.Error())
+		} else if sha != plan.CommitSHA {
+			problems = append(problems, fmt.Sprintf("%s moved from %s to %s since the plan was created", plan.Ref, plan.CommitSHA[:7], sha[:7]))
+		}
+	default:
+		head, err := client.GetLatestCommitSHA(ctx, branch)
+		if err != nil {
+			return fmt.Errorf("failed to get latest commit: %w", err)
+		}
+		if head != plan.CommitSHA {
+			problems = append(problems, fmt.Sprintf("branch '%s' moved from %s to %s since the plan was created", branch, plan.CommitSHA[:7], head[:7]))
+		}
+	}
+
+	if plan.DBChecksum != "" {
+		checksum, err := database.StateChecksum(gitURL, plan.Component)
+		if err != nil {
+			return err
+		}
+		if checksum != plan.DBChecksum {
+			latest, err := database.GetLatestVersion(gitURL, plan.Component)
+			if err != nil {
+				return fmt.Errorf("failed to get latest version: %w", err)
+			}
+			current := "no versions"
+			if latest != nil {
+				current = "v" + latest.Version
+			}
+			problems = append(problems, fmt.Sprintf("qv.db changed since the plan was created from v%s (latest is now %s)", plan.CurrentVersion, current))
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	if forceDeploy {
+		for _, p := range problems {
+			printf("Warning: %s\n", p)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("plan.yaml is stale:\n  %s\nRun 'qv plan' again, or deploy anyway with --force", strings.Join(problems, "\n  "))
+}
+
+// deploySteps runs the steps of a deployment and records each outcome in
+// qv.db. Steps completed by a previous attempt are skipped.
+type deploySteps struct {
+	database     *db.DB
+	deploymentID int
+	previous     map[string]db.DeploymentStep
+}
+
+// completed returns the recorded data of a step finished by a previous
+// attempt
+func (s *deploySteps) completed(step string) (string, bool) {
+	prev, ok := s.previous[step]
+	if !ok || (prev.Status != db.StepDone && prev.Status != db.StepSkipped) {
+		return "", false
+	}
+	return prev.Data, true
+}
+
+// run executes fn unless step was already completed, and records the data
+// fn returns (or its error)
+func (s *deploySteps) run(step string, fn func() (string, error)) (string, error) {
+	if data, ok := s.completed(step); ok {
+		printf("Skipping %s (completed by a previous attempt)\n", step)
+		return data, nil
+	}
+
+	data, err := fn()
+	if err != nil {
+		if recordErr := s.database.SetDeploymentStep(s.deploymentID, step, db.StepFailed, "", err); recordErr != nil {
+			printf("Warning: %v\n", recordErr)
+		}
+		return "", err
+	}
+
+	if err := s.database.SetDeploymentStep(s.deploymentID, step, db.StepDone, data, nil); err != nil {
+		return "", err
+	}
+	return data, nil
+}
+
+// skip records that a step does not apply to this deployment
+func (s *deploySteps) skip(step string) error {
+	if _, ok := s.completed(step); ok {
+		return nil
+	}
+	return s.database.SetDeploymentStep(s.deploymentID, step, db.StepSkipped, "", nil)
+}
+
+// startDeployment records a new deployment, or with --resume reopens the
+// latest failed deployment of the component. A new deployment is refused
+// while a failed one has changed the remote.
+func startDeployment(ctx context.Context, database *db.DB, client forge.Forge, gitURL string, comp *component.Component, nextVersion, tagName string, planData []byte) (*db.Deployment, *deploySteps, error) {
+	deployment := newDeployment(ctx, client, gitURL, comp.Name, nextVersion, tagName, planData)
+
+	unfinished, err := database.GetUnfinishedDeployment(gitURL, comp.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var previous map[string]db.DeploymentStep
+	if unfinished != nil {
+		previous, err = database.GetDeploymentSteps(unfinished.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if resumeDeploy {
+		if unfinished == nil {
+			return nil, nil, fmt.Errorf("no failed deployment of %s to resume", comp.Label())
+		}
+		if unfinished.PlanHash == nil || *unfinished.PlanHash != *deployment.PlanHash {
+			return nil, nil, fmt.Errorf("plan.yaml changed since deployment #%d; run 'qv deploy --rollback' and deploy the new plan", unfinished.ID)
+		}
+		if err := database.ResumeDeployment(unfinished.ID); err != nil {
+			return nil, nil, err
+		}
+		printf("Resuming deployment #%d of %s\n", unfinished.ID, unfinished.TagName)
+		return unfinished, &deploySteps{database: database, deploymentID: unfinished.ID, previous: previous}, nil
+	}
+
+	if unfinished != nil && changedRemote(previous) {
+		return nil, nil, fmt.Errorf("deployment #%d of %s did not finish and already created its tag; run 'qv deploy --resume' or 'qv deploy --rollback'", unfinished.ID, unfinished.TagName)
+	}
+
+	if err := database.StartDeployment(deployment); err != nil {
+		return nil, nil, err
+	}
+	return deployment, &deploySteps{database: database, deploymentID: deployment.ID}, nil
+}
+
+// bumpCommit commits the bump_files rewritten to nextVersion on top of
+// sha and returns the commit to tag. sha is returned unchanged if no
+// bump_files are configured or all of them already hold nextVersion, e.g.
+// after 'qv pr --create-branch' or 'qv release-pr'.
+func bumpCommit(ctx context.Context, client forge.Forge, steps *deploySteps, comp *component.Component, branch, sha, nextVersion, tagName string) (string, error) {
+	files, err := bumpFiles(comp.Name)
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		if err := steps.skip(stepBumpFiles); err != nil {
+			return "", err
+		}
+		return sha, nil
+	}
+
+	// A resumed deploy tags the bump commit it already created
+	if data, ok := steps.completed(stepBumpFiles); ok {
+		printf("Skipping %s (completed by a previous attempt)\n", stepBumpFiles)
+		if data == "" {
+			return sha, nil
+		}
+		return data, nil
+	}
+
+	writer, ok := client.(forge.BranchWriter)
+	if !ok {
+		return "", fmt.Errorf("committing bump_files is not supported by the %s backend; run 'qv bump-files', commit the result and plan again", client.Kind())
+	}
+
+	var changes []forge.FileChange
+	for _, f := range files {
+		name := filepath.ToSlash(f.Path)
+		content, err := writer.GetFile(ctx, name, sha)
+		if errors.Is(err, forge.ErrFileNotFound) {
+			return "", fmt.Errorf("bump file %s not found at %s", name, sha[:7])
+		}
+		if err != nil {
+			return "", err
+		}
+		updated, err := f.Apply(content, nextVersion)
+		if err != nil {
+			return "", err
+		}
+		if !bytes.Equal(updated, content) {
+			changes = append(changes, forge.FileChange{Path: name, Content: updated})
+		}
+	}
+	if len(changes) == 0 {
+		if err := steps.skip(stepBumpFiles); err != nil {
+			return "", err
+		}
+		return sha, nil
+	}
+
+	return steps.run(stepBumpFiles, func() (string, error) {
+		// The bump is committed on the branch, so it must end at sha
+		head, err := client.GetLatestCommitSHA(ctx, branch)
+		if err != nil {
+			return "", fmt.Errorf("failed to get latest commit: %w", err)
+		}
+		if head != sha {
+			return "", fmt.Errorf("bump_files need v%s, but %s is not the head of '%s' to commit them on; bump them on that commit first", nextVersion, sha[:7], branch)
+		}
+
+		printf("Committing %d bump_files to '%s'...\n", len(changes), branch)
+		bumped, err := writer.CommitChanges(ctx, branch, "chore(release): "+tagName, changes)
+		if err != nil {
+			return "", fmt.Errorf("failed to commit bump_files: %w", err)
+		}
+		printf("Bump commit: %s\n", bumped[:7])
+		return bumped, nil
+	})
+}
+
+// taggedCommit returns the commit a deployment tagged: its bump commit,
+// or the resolved commit
+func taggedCommit(steps map[string]db.DeploymentStep) string {
+	if s, ok := steps[stepBumpFiles]; ok && s.Status == db.StepDone && s.Data != "" {
+		return s.Data
+	}
+	return steps[stepResolveCommit].Data
+}
+
+// changedRemote reports whether a deployment created a tag or pushed an
+// image, which a new deployment would conflict with
+func changedRemote(steps map[string]db.DeploymentStep) bool {
+	for _, step := range []string{stepBumpFiles, stepCreateTagObject, stepCreateRef, stepPush} {
+		if s, ok := steps[step]; ok && s.Status == db.StepDone {
+			return true
+		}
+	}
+	return false
+}
+
+// createTag creates the annotated tag for commitSHA. Forges that create the
+// tag object and its ref separately record each as its own step.
+func createTag(ctx context.Context, client forge.Forge, steps *deploySteps, tagName, commitSHA string) error {
+	tagMessage := fmt.Sprintf("Release %s", tagName)
+
+	writer, separate := client.(forge.TagObjectWriter)
+	objectSHA := ""
+	if separate {
+		sha, err := steps.run(stepCreateTagObject, func() (string, error) {
+			printf("Creating tag object '%s'...\n", tagName)
+			return writer.CreateTagObject(ctx, tagName, commitSHA, tagMessage)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create tag: %w", err)
+		}
+		objectSHA = sha
+	} else if err := steps.skip(stepCreateTagObject); err != nil {
+		return err
+	}
+
+	_, err := steps.run(stepCreateRef, func() (string, error) {
+		// A resumed deploy may have created the ref without recording it
+		if resumeDeploy {
+			sha, found, err := remoteTagSHA(ctx, client, tagName)
+			if err != nil {
+				return "", err
+			}
+			if found && sha == commitSHA {
+				printf("Tag '%s' already exists on %s\n", tagName, commitSHA[:7])
+				return "refs/tags/" + tagName, nil
+			}
+		}
+
+		printf("Creating tag '%s'...\n", tagName)
+		var err error
+		if separate {
+			err = writer.CreateTagRef(ctx, tagName, objectSHA)
+		} else {
+			err = client.CreateTag(ctx, tagName, commitSHA, tagMessage)
+		}
+		if err != nil {
+			return "", err
+		}
+		return "refs/tags/" + tagName, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create tag: %w", err)
+	}
+	return nil
+}
+
+// remoteTagSHA returns the commit a tag on the forge points to
+func remoteTagSHA(ctx context.Context, client forge.Forge, tagName string) (string, bool, error) {
+	tags, err := client.ListTags(ctx)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to fetch remote tags: %w", err)
+	}
+	for _, tag := range tags {
+		if tag.Name == tagName {
+			return tag.SHA, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// rollbackDeployment undoes the latest failed deployment of the selected
+// component by deleting the tag it created. plan.yaml is kept so the plan
+// can be deployed again.
+func rollbackDeployment(ctx context.Context, gitURL string) error {
+	comp, err := resolveComponent()
+	if err != nil {
+		return err
+	}
+
+	if !db.Exists() {
+		return fmt.Errorf("database not found. Run 'qv init' first")
+	}
+	database, err := db.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	deployment, err := database.GetUnfinishedDeployment(gitURL, comp.Name)
+	if err != nil {
+		return err
+	}
+	if deployment == nil {
+		return fmt.Errorf("no failed deployment of %s to roll back", comp.Label())
+	}
+
+	steps, err := database.GetDeploymentSteps(deployment.ID)
+	if err != nil {
+		return err
+	}
+	if s, ok := steps[stepRecord]; ok && s.Status == db.StepDone {
+		return fmt.Errorf("deployment #%d already recorded %s in qv.db and cannot be rolled back", deployment.ID, deployment.TagName)
+	}
+
+	client, err := newForge(ctx, gitURL)
+	if err != nil {
+		return err
+	}
+
+	printf("Rolling back deployment #%d of %s...\n", deployment.ID, deployment.TagName)
+	printLine("---")
+
+	// Delete the tag, but only if it still points to the deployed commit.
+	// The local git backend also keeps a local tag for the tag object.
+	deleteTag := steps[stepCreateRef].Status == db.StepDone ||
+		(steps[stepCreateTagObject].Status == db.StepDone && client.Kind() == forge.Git)
+	if deleteTag {
+		if sha, found, err := remoteTagSHA(ctx, client, deployment.TagName); err != nil {
+			return err
+		} else if found && sha != taggedCommit(steps) {
+			return fmt.Errorf("tag %s now points to %s, not the deployed commit; refusing to delete it", deployment.TagName, sha)
+		}
+
+		printf("Deleting tag '%s'...\n", deployment.TagName)
+		if err := client.DeleteTag(ctx, deployment.TagName); err != nil {
+			return err
+		}
+	}
+
+	// The bump commit is part of the branch history now
+	if s, ok := steps[stepBumpFiles]; ok && s.Status == db.StepDone {
+		printf("Warning: bump commit %s stays on the branch\n", shortSHA(s.Data))
+	}
+
+	// Registries are not cleaned up, report what was left behind
+	if s, ok := steps[stepPush]; ok && s.Status == db.StepDone {
+		var result build.Result
+		if err := json.Unmarshal([]byte(steps[stepBuild].Data), &result); err == nil {
+			printLine("Warning: pushed image tags were not removed from the registry:")
+			for _, tag := range result.Tags {
+				printf("  %s\n", tag)
+			}
+		}
+	}
+
+	if err := database.RollBackDeployment(deployment.ID); err != nil {
+		return err
+	}
+
+	printLine()
+	printf("Deployment #%d rolled back. Run 'qv deploy' to deploy %s again.\n", deployment.ID, planFileName)
+	return nil
+}
+
+// newDeployment describes a deploy for the audit log. The actor is the
+// forge user the token belongs to, falling back to the local user name.
+func newDeployment(ctx context.Context, client forge.Forge, gitURL, componentName, nextVersion, tagName string, planData []byte) *db.Deployment {
+	actor := currentActor(ctx, client)
+	planHash := fmt.Sprintf("%x", sha256.Sum256(planData))
+
+	d := &db.Deployment{
+		GitURL:    gitURL,
+		Component: componentName,
+		Version:   nextVersion,
+		TagName:   tagName,
+		Actor:     &actor,
+		PlanHash:  &planHash,
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		d.Hostname = &hostname
+	}
+	return d
+}
+
+// previousRelease returns the release a plan follows: the latest version of
+// its release line, or the latest version if the plan has no line
+func previousRelease(database *db.DB, gitURL string, comp *component.Component, planLine string) (*db.Version, error) {
+	if planLine == "" {
+		latest, err := database.GetLatestVersion(gitURL, comp.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get latest version: %w", err)
+		}
+		return latest, nil
+	}
+
+	line, err := version.ParseLine(planLine)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := database.GetLatestVersionInLine(gitURL, comp.Name, line)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest version: %w", err)
+	}
+	return latest, nil
+}
+
+// currentActor returns the forge user qv runs as, or the local user for
+// forges without accounts
+func currentActor(ctx context.Context, client forge.Forge) string {
+	actor, err := client.CurrentUser(ctx)
+	if err != nil || actor == "" {
+		return localUser()
+	}
+	return actor
+}
+
+// localUser returns the name of the user running qv
+func localUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// buildImage builds the configured Containerfile with buildah, tags it with
+// the new version and its aliases, and pushes the tags to build.registry if
+// set. It returns the image record to store with the version.
+func buildImage(ctx context.Context, steps *deploySteps, gitURL, componentName, nextVersion string) (*build.Result, *db.Image, error) {
+	history, err := steps.database.GetVersionHistory(gitURL, componentName)
+	if err != nil {
+		return nil, nil, err
+	}
+	released := make([]string, len(history))
+	for i, v := range history {
+		released[i] = v.Version
+	}
+
+	registry := config.GetBuildRegistry()
+	opts := build.Options{
+		Context:       config.GetBuildContext(),
+		Containerfile: config.GetBuildContainerfile(),
+		Image:         build.ImageName(registry, config.GetBuildImage()),
+		Version:       nextVersion,
+		BuildArgs:     config.GetBuildArgs(),
+		Released:      released,
+		Output:        progress(),
+	}
+
+	data, err := steps.run(stepBuild, func() (string, error) {
+		printf("Building image '%s' with buildah...\n", opts.Image)
+		result, err := build.Build(ctx, opts)
+		if err != nil {
+			return "", err
+		}
+		encoded, err := json.Marshal(result)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode build result: %w", err)
+		}
+		return string(encoded), nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var result build.Result
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode build result: %w", err)
+	}
+
+	img := &db.Image{
+		GitURL:    gitURL,
+		Component: componentName,
+		Version:   nextVersion,
+		Image:     opts.Image,
+		ImageID:   result.ImageID,
+		Digest:    result.Digest,
+		Tags:      strings.Join(result.Tags, ","),
+	}
+
+	// Push every tag when a registry is configured
+	if registry == "" {
+		if err := steps.skip(stepPush); err != nil {
+			return nil, nil, err
+		}
+		return &result, img, nil
+	}
+
+	digest, err := steps.run(stepPush, func() (string, error) {
+		pushOpts := build.PushOptions{
+			Username:  config.GetRegistryUsername(),
+			Password:  config.GetRegistryPassword(),
+			TLSVerify: config.GetRegistryTLSVerify(),
+			Output:    progress(),
+		}
+		return build.Push(ctx, result.Tags, pushOpts)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	img.PushedDigest = &digest
+	result.PushedDigest = digest
+
+	return &result, img, nil
+}
+
+// publishRelease creates a forge release for tagName using the generated
+// notes. Pre-release versions are always marked as pre-releases.
+func publishRelease(ctx context.Context, client forge.Forge, tagName, nextVersion string, notes *changelog.Entry) (*forge.Release, error) {
+	body := ""
+	if notes != nil {
+		body = notes.Markdown()
+	}
+
+	prerelease := config.GetReleasePrerelease()
+	if parsed, err := version.Parse(nextVersion); err == nil && parsed.IsPreRelease() {
+		prerelease = true
+	}
+
+	printf("Creating release '%s'...\n", tagName)
+	release, err := client.CreateRelease(ctx, tagName, tagName, body, config.GetReleaseDraft(), prerelease)
+	if err != nil {
+		return nil, err
+	}
+	return release, nil
+}
+
+// DeployOutput is the machine-readable result of qv deploy
+type DeployOutput struct {
+	DeploymentID int          `yaml:"deployment_id" json:"deployment_id"`
+	Component    string       `yaml:"component,omitempty" json:"component,omitempty"`
+	Version      string       `yaml:"version" json:"version"`
+	Tag          string       `yaml:"tag" json:"tag"`
+	CommitSHA    string       `yaml:"commit_sha" json:"commit_sha"`
+	Image        *DeployImage `yaml:"image,omitempty" json:"image,omitempty"`
+	ReleaseURL   string       `yaml:"release_url,omitempty" json:"release_url,omitempty"`
+	Changelog    bool         `yaml:"changelog" json:"changelog"`
+}
+
+// DeployImage describes the image built by a deployment
+type DeployImage struct {
+	ID           string   `yaml:"id" json:"id"`
+	Digest       string   `yaml:"digest" json:"digest"`
+	PushedDigest string   `yaml:"pushed_digest,omitempty" json:"pushed_digest,omitempty"`
+	Tags         []string `yaml:"tags" json:"tags"`
+}
+
 func init() {
 	rootCmd.AddCommand(deployCmd)
-	deployCmd.Flags().StringVar(&targetBranch, "branch", "main", "branch to tag")
+	deployCmd.Flags().StringVar(&targetBranch, "branch", "", "branch to tag (default is the branch in plan.yaml)")
+	deployCmd.Flags().BoolVar(&forceDeploy, "force", false, "deploy even if plan.yaml is stale")
+	addTargetFlags(deployCmd)
+	deployCmd.Flags().BoolVar(&resumeDeploy, "resume", false, "resume the latest failed deployment from the step that failed")
+	deployCmd.Flags().BoolVar(&rollbackDeploy, "rollback", false, "delete the tag created by the latest failed deployment")
+	deployCmd.Flags().BoolVar(&fromMergedPR, "from-merged-pr", false, "deploy the version recorded in the latest merged release PR (see 'qv release-pr')")
+	deployCmd.Flags().StringVar(&releaseLabel, "label", defaultReleaseLabel, "label that marks the release PR, with --from-merged-pr")
+	deployCmd.MarkFlagsMutuallyExclusive("resume", "rollback")
+	deployCmd.MarkFlagsMutuallyExclusive("from-merged-pr", "rollback")
+	addComponentFlag(deployCmd)
+	addOutputFlag(deployCmd)
 }
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/excircle/quik-version/internal/config"
+	"github.com/excircle/quik-version/internal/forge"
+	"github.com/excircle/quik-version/internal/gitea"
+	"github.com/excircle/quik-version/internal/github"
+	"github.com/excircle/quik-version/internal/gitlab"
+	"github.com/excircle/quik-version/internal/gitlocal"
+)
+
+// newForge creates a forge client for gitURL, selected by version.forge or
+// detected from the URL host
+func newForge(ctx context.Context, gitURL string) (forge.Forge, error) {
+	kind, err := forge.Detect(gitURL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case forge.GitHub:
+		repo, err := github.NewRepository(ctx, gitURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GitHub client: %w", err)
+		}
+		return repo, nil
+	case forge.GitLab:
+		client, err := gitlab.NewClient(ctx, gitURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GitLab client: %w", err)
+		}
+		return client, nil
+	case forge.Gitea:
+		client, err := gitea.NewClient(ctx, gitURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Gitea client: %w", err)
+		}
+		return client, nil
+	case forge.Git:
+		repo, err := gitlocal.NewRepository(config.GetRepoPath(), config.GetRemote())
+		if err != nil {
+			return nil, fmt.Errorf("failed to open local git repository: %w", err)
+		}
+		return repo, nil
+	default:
+		return nil, fmt.Errorf("unsupported forge %q", kind)
+	}
+}
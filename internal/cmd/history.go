@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/excircle/quik-version/internal/component"
+	"github.com/excircle/quik-version/internal/config"
+	"github.com/excircle/quik-version/internal/db"
+	"github.com/excircle/quik-version/internal/version"
+)
+
+var (
+	historyVersion string
+	historyActor   string
+	historyOutcome string
+	historyLimit   int
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List past deployments",
+	Long: `History lists the deployments recorded in qv.db by 'qv deploy',
+newest first.
+
+Each deployment records the version and tag, the forge user that ran it
+(or the local user for the git backend), the host it ran on, a hash of
+the deployed plan.yaml, start and finish times, the outcome and, for
+failed deployments, the error.
+
+Without --component, deployments of every component are listed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Check if database exists
+		if !db.Exists() {
+			return fmt.Errorf("database not found. Run 'qv init' first")
+		}
+
+		// Get git URL from config
+		gitURL := config.GetGitURL()
+		if gitURL == "" {
+			return fmt.Errorf("git_url not configured. Run 'qv init' first")
+		}
+
+		switch historyOutcome {
+		case "", db.OutcomeRunning, db.OutcomeSuccess, db.OutcomeFailed, db.OutcomeRolledBack:
+		default:
+			return fmt.Errorf("invalid outcome %q (must be running, success, failed or rolled_back)", historyOutcome)
+		}
+
+		// Resolve the component to filter on
+		comp, err := resolveComponent()
+		if err != nil {
+			return err
+		}
+
+		// Open database
+		database, err := db.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer database.Close()
+
+		filter := db.DeploymentFilter{
+			GitURL:        gitURL,
+			Component:     comp.Name,
+			AllComponents: componentName == "",
+			Actor:         historyActor,
+			Outcome:       historyOutcome,
+			Limit:         historyLimit,
+		}
+		if historyVersion != "" {
+			parsed, err := version.Parse(historyVersion)
+			if err != nil {
+				return fmt.Errorf("invalid version %q: %w", historyVersion, err)
+			}
+			filter.Version = parsed.String()
+		}
+
+		deployments, err := database.GetDeployments(filter)
+		if err != nil {
+			return err
+		}
+
+		if len(deployments) == 0 {
+			fmt.Println("No deployments recorded.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tCOMPONENT\tVERSION\tTAG\tACTOR\tHOST\tSTARTED\tFINISHED\tOUTCOME")
+		for _, d := range deployments {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				d.ID, (&component.Component{Name: d.Component}).Label(), d.Version, d.TagName,
+				valueOr(d.Actor, "-"), valueOr(d.Hostname, "-"), d.StartedAt,
+				valueOr(d.FinishedAt, "-"), d.Outcome)
+		}
+		w.Flush()
+
+		// Errors are too long for the table, list them afterwards
+		for _, d := range deployments {
+			if d.Error != nil {
+				fmt.Printf("\n#%d %s (%s): %s\n", d.ID, d.TagName, d.Outcome, *d.Error)
+			}
+		}
+
+		return nil
+	},
+}
+
+// valueOr dereferences s, or returns fallback if s is nil
+func valueOr(s *string, fallback string) string {
+	if s == nil {
+		return fallback
+	}
+	return *s
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.Flags().StringVar(&historyVersion, "version", "", "only show deployments of this version")
+	historyCmd.Flags().StringVar(&historyActor, "actor", "", "only show deployments run by this user")
+	historyCmd.Flags().StringVar(&historyOutcome, "outcome", "", "only show deployments with this outcome (running, success, failed, rolled_back)")
+	historyCmd.Flags().IntVar(&historyLimit, "limit", 20, "maximum number of deployments to show (0 for all)")
+	addComponentFlag(historyCmd)
+}
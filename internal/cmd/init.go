@@ -1,19 +1,27 @@
 package cmd
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"os"
-	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
 
 	"github.com/excircle/quik-version/internal/db"
+	"github.com/excircle/quik-version/internal/forge"
+	"github.com/excircle/quik-version/internal/prompt"
 )
 
 const configFileName = "quik.conf"
 
+var (
+	initGitURL    string
+	initDBPath    string
+	initSaveToken bool
+)
+
 type quikConfig struct {
 	Version struct {
 		GitURL string `yaml:"git_url"`
@@ -35,48 +43,91 @@ var initCmd = &cobra.Command{
 This command will:
 - Check for existing quik.conf and prompt to overwrite or skip
 - Prompt for git_url
-- Prompt for GitHub token
-- Create qv.db with the required schema`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		reader := bufio.NewReader(os.Stdin)
+- Prompt for the forge token
+- Create qv.db, or migrate an existing qv.db to the latest schema
+  without losing its version history
+
+Every prompt has a flag, so init can run without a terminal:
 
+  qv init --yes --git-url https://github.com/user/repo --db-path .qv
+
+With --save-token and no token entered, the token is read from the
+forge's environment variable (GITHUB_TOKEN, GITLAB_TOKEN or GITEA_TOKEN).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		// Check for existing quik.conf
 		if _, err := os.Stat(configFileName); err == nil {
-			fmt.Printf("%s already exists. Overwrite? (y/n): ", configFileName)
-			response, _ := reader.ReadString('\n')
-			response = strings.TrimSpace(strings.ToLower(response))
-			if response != "y" && response != "yes" {
+			overwrite, err := prompt.Confirm(fmt.Sprintf("%s already exists. Overwrite?", configFileName))
+			if errors.Is(err, prompt.ErrNoInput) {
+				return fmt.Errorf("%s already exists; rerun with --yes to overwrite it", configFileName)
+			}
+			if err != nil {
+				return err
+			}
+			if !overwrite {
 				fmt.Println("Skipping config creation.")
 				return nil
 			}
 		}
 
 		// Prompt for git_url
-		fmt.Print("Enter git URL (e.g., https://github.com/user/repo): ")
-		gitURL, _ := reader.ReadString('\n')
-		gitURL = strings.TrimSpace(gitURL)
+		gitURL := initGitURL
+		if gitURL == "" {
+			var err error
+			gitURL, err = prompt.String("Enter git URL (e.g., https://github.com/user/repo): ")
+			if errors.Is(err, prompt.ErrNoInput) {
+				return fmt.Errorf("--git-url is required when not running interactively")
+			}
+			if err != nil {
+				return err
+			}
+		}
 		if gitURL == "" {
 			return fmt.Errorf("git URL cannot be empty")
 		}
 
-		// Prompt for GitHub token
-		fmt.Print("Enter GitHub token (leave empty to use GITHUB_TOKEN env var): ")
-		token, _ := reader.ReadString('\n')
-		token = strings.TrimSpace(token)
+		// The token's environment variable depends on the forge
+		envVar := "GITHUB_TOKEN"
+		if kind, err := forge.Detect(gitURL); err == nil {
+			envVar = forge.TokenEnvVar(kind)
+		}
+
+		// Prompt for the token
+		var token string
+		if envVar != "" && prompt.Interactive() {
+			var err error
+			token, err = prompt.String(fmt.Sprintf("Enter token (leave empty to use %s env var): ", envVar))
+			if err != nil {
+				return err
+			}
+		}
 
 		// Prompt for save token preference
-		saveToken := false
-		if token != "" {
-			fmt.Print("Save token to config file? (y/n): ")
-			response, _ := reader.ReadString('\n')
-			response = strings.TrimSpace(strings.ToLower(response))
-			saveToken = response == "y" || response == "yes"
+		saveToken := initSaveToken
+		if !cmd.Flags().Changed("save-token") && token != "" {
+			var err error
+			saveToken, err = prompt.Confirm("Save token to config file?")
+			if err != nil {
+				return err
+			}
+		}
+		if saveToken && token == "" {
+			if envVar != "" {
+				token = os.Getenv(envVar)
+			}
+			if token == "" {
+				return fmt.Errorf("--save-token was given, but no token was entered and %s is not set", envVar)
+			}
 		}
 
 		// Prompt for db path
-		fmt.Print("Enter database path (leave empty for current directory): ")
-		dbPath, _ := reader.ReadString('\n')
-		dbPath = strings.TrimSpace(dbPath)
+		dbPath := initDBPath
+		if !cmd.Flags().Changed("db-path") && prompt.Interactive() {
+			var err error
+			dbPath, err = prompt.String("Enter database path (leave empty for current directory): ")
+			if err != nil {
+				return err
+			}
+		}
 
 		// Create config
 		config := quikConfig{}
@@ -98,22 +149,13 @@ This command will:
 		}
 		fmt.Printf("Created %s\n", configFileName)
 
-		// Check for existing database
-		if db.Exists() {
-			fmt.Printf("qv.db already exists. Overwrite? (y/n): ")
-			response, _ := reader.ReadString('\n')
-			response = strings.TrimSpace(strings.ToLower(response))
-			if response != "y" && response != "yes" {
-				fmt.Println("Skipping database creation.")
-				return nil
-			}
-			// Remove existing database
-			if err := os.Remove(db.GetDBPath()); err != nil {
-				return fmt.Errorf("failed to remove existing database: %w", err)
-			}
-		}
+		// The database goes where the new config says
+		viper.Set("storage.db_path", dbPath)
 
-		// Create and initialize database
+		// An existing database keeps its version history and is migrated
+		existed := db.Exists()
+
+		// Create or migrate database
 		database, err := db.Open()
 		if err != nil {
 			return fmt.Errorf("failed to open database: %w", err)
@@ -128,7 +170,16 @@ This command will:
 			return fmt.Errorf("failed to set config state: %w", err)
 		}
 
-		fmt.Printf("Created %s\n", db.GetDBPath())
+		schemaVersion, err := database.SchemaVersion()
+		if err != nil {
+			return err
+		}
+
+		if existed {
+			fmt.Printf("Updated %s (schema version %d)\n", db.GetDBPath(), schemaVersion)
+		} else {
+			fmt.Printf("Created %s (schema version %d)\n", db.GetDBPath(), schemaVersion)
+		}
 		fmt.Println("Initialization complete!")
 
 		return nil
@@ -137,4 +188,7 @@ This command will:
 
 func init() {
 	rootCmd.AddCommand(initCmd)
+	initCmd.Flags().StringVar(&initGitURL, "git-url", "", "repository URL to write to quik.conf")
+	initCmd.Flags().StringVar(&initDBPath, "db-path", "", "directory for qv.db (default is the current directory)")
+	initCmd.Flags().BoolVar(&initSaveToken, "save-token", false, "save the forge token to quik.conf")
 }
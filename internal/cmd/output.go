@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var outputFormat string
+
+// addOutputFlag registers --output on a command
+func addOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", "print the result as json or yaml instead of text")
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		switch outputFormat {
+		case "", "json", "yaml":
+			return nil
+		default:
+			return fmt.Errorf("invalid output format %q (must be json or yaml)", outputFormat)
+		}
+	}
+}
+
+// machineOutput reports whether --output selected a machine-readable format
+func machineOutput() bool {
+	return outputFormat != ""
+}
+
+// progress returns where human-readable messages go. With --output they
+// go to stderr so stdout only carries the json or yaml document.
+func progress() io.Writer {
+	if machineOutput() {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// printf writes a human-readable message
+func printf(format string, a ...any) {
+	fmt.Fprintf(progress(), format, a...)
+}
+
+// printLine writes a human-readable line
+func printLine(a ...any) {
+	fmt.Fprintln(progress(), a...)
+}
+
+// writeOutput prints v to stdout in the format selected with --output
+func writeOutput(v any) error {
+	switch outputFormat {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case "yaml":
+		enc := yaml.NewEncoder(os.Stdout)
+		enc.SetIndent(2)
+		defer enc.Close()
+		return enc.Encode(v)
+	default:
+		return fmt.Errorf("invalid output format %q", outputFormat)
+	}
+}
@@ -1,20 +1,32 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 
+	"github.com/excircle/quik-version/internal/component"
 	"github.com/excircle/quik-version/internal/config"
+	"github.com/excircle/quik-version/internal/conventional"
 	"github.com/excircle/quik-version/internal/db"
+	"github.com/excircle/quik-version/internal/forge"
 	"github.com/excircle/quik-version/internal/version"
 )
 
 var (
-	majorFlag bool
-	patchFlag bool
+	majorFlag   bool
+	patchFlag   bool
+	preChannel  string
+	promoteFlag bool
+	autoFlag    bool
+	labelsFlag  bool
+	planBranch  string
+	planLine    string
+	planVersion string
 )
 
 var planCmd = &cobra.Command{
@@ -27,11 +39,50 @@ By default, increments the MINOR version.
 Use --major to increment MAJOR (reset minor/patch).
 Use --patch to increment PATCH only.
 
-Generates plan.yaml with the version bump details.`,
+Use --pre <channel> (alpha, beta or rc) to plan a pre-release such as
+1.5.0-rc.1; running it again on the same channel plans 1.5.0-rc.2.
+Use --promote to turn the latest pre-release into its final version.
+
+Use --auto to choose the increment from Conventional Commits between the
+last recorded release commit and the head of --branch:
+BREAKING CHANGE or '!' selects MAJOR, feat selects MINOR and fix or perf
+selects PATCH. The contributing commits are recorded in plan.yaml.
+
+Use --from-labels to choose the increment from the labels of the pull
+requests merged into --branch since the last recorded release commit:
+semver:major, semver:minor and semver:patch select that increment and
+semver:none marks a change that needs no release. The highest label
+wins, and planning is refused while a merged pull request has no semver
+label. The pull requests are recorded in plan.yaml.
+
+Use --line MAJOR.MINOR (e.g. --line 1.8) to plan the next patch release of
+an older release line, such as 1.8.4 after 2.0.0 has shipped. The plan
+targets the line's maintenance branch (release/1.8 unless configured in
+lines in quik.conf).
+
+Use --version to plan a given version instead of calculating it, e.g. to
+re-plan the version of a merged release PR on its merge commit. It must be
+higher than the current version (of the release line with --line).
+
+Use --component to version one component of a monorepo; with --auto only
+commits that touched the component's path are considered.
+
+The plan pins the head commit of --branch and a checksum of the versions
+in qv.db. 'qv deploy' tags exactly that commit, and refuses to deploy if
+the branch has moved or qv.db has changed since the plan was created.
+
+Use --sha or --ref to release an older commit or a hotfix commit instead
+of the branch head. The commit must exist on the remote and be reachable
+from --branch, and a version lower than one already tagged on a
+descendant commit is refused.
+
+Generates plan.yaml with the version bump details. With --output json or
+--output yaml the plan is also printed in that format.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Validate flags
-		if majorFlag && patchFlag {
-			return fmt.Errorf("cannot use both --major and --patch flags")
+		ctx := context.Background()
+
+		if err := validatePlanFlags(); err != nil {
+			return err
 		}
 
 		// Check if database exists
@@ -52,49 +103,169 @@ Generates plan.yaml with the version bump details.`,
 		}
 		defer database.Close()
 
-		// Get latest version
-		latestVersion, err := database.GetLatestVersion(gitURL)
+		// Resolve the component being versioned
+		comp, err := resolveComponent()
+		if err != nil {
+			return err
+		}
+
+		// Get latest version, of the release line if one was selected
+		var latestVersion *db.Version
+		if planLine != "" {
+			line, err := version.ParseLine(planLine)
+			if err != nil {
+				return err
+			}
+			planLine = line.String()
+			if !cmd.Flags().Changed("branch") {
+				planBranch = config.GetLineBranch(planLine)
+			}
+
+			latestVersion, err = database.GetLatestVersionInLine(gitURL, comp.Name, line)
+			if err != nil {
+				return fmt.Errorf("failed to get latest version: %w", err)
+			}
+			if latestVersion == nil {
+				return fmt.Errorf("no versions recorded in release line %s", planLine)
+			}
+		} else {
+			latestVersion, err = database.GetLatestVersion(gitURL, comp.Name)
+			if err != nil {
+				return fmt.Errorf("failed to get latest version: %w", err)
+			}
+		}
+
+		// Pin the state the plan is computed from
+		checksum, err := database.StateChecksum(gitURL, comp.Name)
+		if err != nil {
+			return err
+		}
+
+		client, err := newForge(ctx, gitURL)
 		if err != nil {
-			return fmt.Errorf("failed to get latest version: %w", err)
+			return err
+		}
+
+		ref, err := explicitTarget()
+		if err != nil {
+			return err
+		}
+
+		var commitSHA string
+		if ref != "" {
+			commitSHA, err = resolveTarget(ctx, client, ref, planBranch)
+			if err != nil {
+				return err
+			}
+		} else {
+			commitSHA, err = client.GetLatestCommitSHA(ctx, planBranch)
+			if err != nil {
+				return fmt.Errorf("failed to get latest commit: %w", err)
+			}
 		}
 
 		// Determine increment type and calculate next version
-		var incrementType string
+		incrementType := "minor"
+		if majorFlag {
+			incrementType = "major"
+		} else if patchFlag || planLine != "" {
+			incrementType = "patch"
+		}
+
+		var planCommits []PlanCommit
+		if autoFlag {
+			incrementType, planCommits, err = analyzeCommits(ctx, client, comp, latestVersion, commitSHA)
+			if err != nil {
+				return err
+			}
+			if incrementType == "" {
+				return fmt.Errorf("no releasable commits found for %s on '%s' since the last release", comp.Label(), planBranch)
+			}
+		}
+
+		var planPRs []PlanPR
+		if labelsFlag {
+			incrementType, planPRs, err = analyzeLabels(ctx, client, comp, latestVersion, commitSHA)
+			if err != nil {
+				return err
+			}
+			if incrementType == "" {
+				return fmt.Errorf("no pull requests labeled semver:major, semver:minor or semver:patch were merged into '%s' since the last release", planBranch)
+			}
+		}
+
 		var currentVersion string
 		var nextVersion string
+		channel := preChannel
 
-		if latestVersion == nil {
+		if planVersion != "" {
+			currentVersion = "0.0.0"
+			if latestVersion != nil {
+				currentVersion = latestVersion.Version
+			}
+			next, err := explicitVersion(planVersion, currentVersion)
+			if err != nil {
+				return err
+			}
+			nextVersion = next.String()
+			incrementType = explicitIncrement(currentVersion, next)
+			channel = next.Channel()
+		} else if latestVersion == nil {
+			if promoteFlag {
+				return fmt.Errorf("no versions recorded yet, nothing to promote")
+			}
 			currentVersion = "0.0.0"
-			if majorFlag {
-				incrementType = "major"
-				nextVersion = "1.0.0"
-			} else if patchFlag {
-				incrementType = "patch"
-				nextVersion = "0.0.1"
+			if preChannel != "" {
+				nextVersion = version.InitialPreRelease(incrementType, preChannel)
 			} else {
-				incrementType = "minor"
-				nextVersion = "0.1.0"
+				nextVersion = version.Initial(incrementType)
 			}
 		} else {
 			currentVersion = latestVersion.Version
-			if majorFlag {
-				incrementType = "major"
-				nextVersion = version.IncrementMajor(currentVersion)
-			} else if patchFlag {
-				incrementType = "patch"
-				nextVersion = version.IncrementPatch(currentVersion)
-			} else {
-				incrementType = "minor"
-				nextVersion = version.IncrementMinor(currentVersion)
+			current, err := version.Parse(currentVersion)
+			if err != nil {
+				return fmt.Errorf("failed to parse current version: %w", err)
+			}
+
+			switch {
+			case promoteFlag:
+				if !current.IsPreRelease() {
+					return fmt.Errorf("latest version v%s is not a pre-release, nothing to promote", currentVersion)
+				}
+				incrementType = "promote"
+				nextVersion = current.Core().String()
+			case preChannel != "":
+				// Without an explicit increment a pre-release keeps its core version
+				preIncrement := incrementType
+				if !majorFlag && !patchFlag && planLine == "" && !autoFlag && !labelsFlag {
+					preIncrement = ""
+				}
+				nextVersion = current.NextPreRelease(preIncrement, preChannel).String()
+			default:
+				nextVersion = current.Next(incrementType).String()
 			}
 		}
 
+		// Versions must not decrease along the history
+		if err := checkDescendantVersions(ctx, client, database, gitURL, comp, nextVersion, commitSHA); err != nil {
+			return err
+		}
+
 		// Create plan
 		plan := PlanFile{
 			GitURL:         gitURL,
+			Component:      comp.Name,
 			CurrentVersion: currentVersion,
 			NextVersion:    nextVersion,
 			IncrementType:  incrementType,
+			Channel:        channel,
+			Line:           planLine,
+			Branch:         planBranch,
+			Ref:            ref,
+			CommitSHA:      commitSHA,
+			DBChecksum:     checksum,
+			Commits:        planCommits,
+			PullRequests:   planPRs,
 		}
 
 		// Write plan.yaml
@@ -107,13 +278,46 @@ Generates plan.yaml with the version bump details.`,
 			return fmt.Errorf("failed to write plan file: %w", err)
 		}
 
+		if machineOutput() {
+			return writeOutput(PlanOutput{PlanFile: plan, Tag: comp.TagName(nextVersion), File: planFileName})
+		}
+
 		// Display summary
 		fmt.Println("Plan created:")
 		fmt.Println("---")
 		fmt.Printf("Repository: %s\n", gitURL)
+		if comp.Name != "" {
+			fmt.Printf("Component: %s\n", comp.Name)
+		}
 		fmt.Printf("Current Version: v%s\n", currentVersion)
 		fmt.Printf("Next Version: v%s\n", nextVersion)
+		fmt.Printf("Tag: %s\n", comp.TagName(nextVersion))
+		if targetRef != "" {
+			fmt.Printf("Commit: %s (%s on %s)\n", commitSHA[:7], ref, planBranch)
+		} else if targetSHA != "" {
+			fmt.Printf("Commit: %s (on %s)\n", commitSHA[:7], planBranch)
+		} else {
+			fmt.Printf("Commit: %s (%s)\n", commitSHA[:7], planBranch)
+		}
 		fmt.Printf("Increment Type: %s\n", incrementType)
+		if channel != "" {
+			fmt.Printf("Channel: %s\n", channel)
+		}
+		if planLine != "" {
+			fmt.Printf("Release Line: %s\n", planLine)
+		}
+		if len(planCommits) > 0 {
+			fmt.Printf("Contributing commits (%d):\n", len(planCommits))
+			for _, c := range planCommits {
+				fmt.Printf("  %s %s: %s\n", c.SHA[:7], c.Type, c.Subject)
+			}
+		}
+		if len(planPRs) > 0 {
+			fmt.Printf("Merged pull requests (%d):\n", len(planPRs))
+			for _, pr := range planPRs {
+				fmt.Printf("  #%d %s: %s\n", pr.Number, pr.Label, pr.Title)
+			}
+		}
 		fmt.Println()
 		fmt.Printf("Plan saved to %s\n", planFileName)
 		fmt.Println("Run 'qv deploy' to apply this plan.")
@@ -122,8 +326,233 @@ Generates plan.yaml with the version bump details.`,
 	},
 }
 
+// validatePlanFlags rejects combinations of the increment flags that
+// contradict each other
+func validatePlanFlags() error {
+	if majorFlag && patchFlag {
+		return fmt.Errorf("cannot use both --major and --patch flags")
+	}
+	if promoteFlag && (majorFlag || patchFlag || preChannel != "") {
+		return fmt.Errorf("--promote cannot be combined with --major, --patch or --pre")
+	}
+	if autoFlag && (majorFlag || patchFlag || promoteFlag) {
+		return fmt.Errorf("--auto cannot be combined with --major, --patch or --promote")
+	}
+	if labelsFlag && (majorFlag || patchFlag || promoteFlag || autoFlag) {
+		return fmt.Errorf("--from-labels cannot be combined with --major, --patch, --promote or --auto")
+	}
+	if planLine != "" && (majorFlag || promoteFlag || autoFlag || labelsFlag) {
+		return fmt.Errorf("--line cannot be combined with --major, --promote, --auto or --from-labels")
+	}
+	if planVersion != "" && (majorFlag || patchFlag || preChannel != "" || promoteFlag || autoFlag || labelsFlag) {
+		return fmt.Errorf("--version cannot be combined with --major, --patch, --pre, --promote, --auto or --from-labels")
+	}
+	if preChannel != "" && !version.IsChannel(preChannel) {
+		return fmt.Errorf("invalid pre-release channel %q (expected one of: %s)", preChannel, strings.Join(version.Channels, ", "))
+	}
+	return nil
+}
+
+// explicitVersion validates a version given with --version: it must be
+// higher than the current version and, with --line, belong to the line
+func explicitVersion(v, currentVersion string) (*version.Version, error) {
+	next, err := version.Parse(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --version: %w", err)
+	}
+	current, err := version.Parse(currentVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse current version: %w", err)
+	}
+	if !current.LessThan(next) {
+		return nil, fmt.Errorf("--version v%s is not higher than the current version v%s", next, currentVersion)
+	}
+	if planLine != "" {
+		line, err := version.ParseLine(planLine)
+		if err != nil {
+			return nil, err
+		}
+		if !line.Contains(next) {
+			return nil, fmt.Errorf("--version v%s is not in release line %s", next, planLine)
+		}
+	}
+	return next, nil
+}
+
+// explicitIncrement returns the increment type recorded for a version
+// given with --version: the highest part of the core version that
+// changed, or "promote" for the final version of a pre-release
+func explicitIncrement(currentVersion string, next *version.Version) string {
+	current, err := version.Parse(currentVersion)
+	if err != nil {
+		return "minor"
+	}
+
+	switch {
+	case next.Major != current.Major:
+		return "major"
+	case next.Minor != current.Minor:
+		return "minor"
+	case next.Patch != current.Patch:
+		return "patch"
+	case current.IsPreRelease() && !next.IsPreRelease():
+		return "promote"
+	case next.Patch != 0:
+		// Another pre-release of the same core version
+		return "patch"
+	case next.Minor != 0:
+		return "minor"
+	default:
+		return "major"
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(planCmd)
 	planCmd.Flags().BoolVar(&majorFlag, "major", false, "increment major version")
 	planCmd.Flags().BoolVar(&patchFlag, "patch", false, "increment patch version")
+	planCmd.Flags().StringVar(&preChannel, "pre", "", "plan a pre-release on a channel (alpha, beta, rc)")
+	planCmd.Flags().BoolVar(&autoFlag, "auto", false, "choose the increment from Conventional Commits since the last release")
+	planCmd.Flags().BoolVar(&labelsFlag, "from-labels", false, "choose the increment from the semver labels of pull requests merged since the last release")
+	planCmd.Flags().StringVar(&planBranch, "branch", "main", "branch whose head commit is planned for release")
+	planCmd.Flags().BoolVar(&promoteFlag, "promote", false, "promote the latest pre-release to its final version")
+	planCmd.Flags().StringVar(&planLine, "line", "", "plan the next patch release of an older MAJOR.MINOR release line")
+	planCmd.Flags().StringVar(&planVersion, "version", "", "plan this version instead of calculating the next one")
+	addTargetFlags(planCmd)
+	addComponentFlag(planCmd)
+	addOutputFlag(planCmd)
+}
+
+// PlanOutput is the machine-readable result of qv plan
+type PlanOutput struct {
+	PlanFile `yaml:",inline"`
+	Tag      string `yaml:"tag" json:"tag"`
+	File     string `yaml:"file" json:"file"`
+}
+
+// analyzeCommits lists the commits up to head since the latest recorded
+// release that touched the component, and picks the increment type from
+// their Conventional Commit headers. The increment type is empty if none
+// of the commits is releasable.
+func analyzeCommits(ctx context.Context, client forge.Forge, comp *component.Component, latestVersion *db.Version, head string) (string, []PlanCommit, error) {
+	commits, err := releaseCommits(ctx, client, comp, latestVersion, head)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var parsed []*conventional.Commit
+	var planCommits []PlanCommit
+	for _, c := range commits {
+		commit, err := conventional.Parse(c.Message)
+		if err != nil || commit.Increment() == "" {
+			continue // Not a releasable conventional commit
+		}
+		parsed = append(parsed, commit)
+		planCommits = append(planCommits, PlanCommit{
+			SHA:      c.SHA,
+			Type:     commit.Type,
+			Scope:    commit.Scope,
+			Subject:  commit.Subject,
+			Breaking: commit.Breaking,
+		})
+	}
+
+	incrementType := conventional.Increment(parsed)
+	if incrementType == "" {
+		return "", nil, nil
+	}
+
+	return incrementType, planCommits, nil
+}
+
+// releaseCommits lists the commits up to head since the latest recorded
+// release that touched the component, oldest first
+func releaseCommits(ctx context.Context, client forge.Forge, comp *component.Component, latestVersion *db.Version, head string) ([]forge.Commit, error) {
+	var commits []forge.Commit
+	var err error
+	if latestVersion == nil {
+		commits, err = client.ListCommits(ctx, head)
+	} else {
+		commits, err = client.CompareCommits(ctx, latestVersion.GitSHA, head)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits: %w", err)
+	}
+
+	return filterComponentCommits(ctx, client, comp, commits)
+}
+
+// semverLabels maps the pull request labels read by --from-labels to
+// increment types. semver:none marks a change that needs no release.
+var semverLabels = map[string]string{
+	"semver:major": "major",
+	"semver:minor": "minor",
+	"semver:patch": "patch",
+	"semver:none":  "",
+}
+
+// analyzeLabels finds the pull requests merged into the plan's branch
+// with commits since the latest recorded release that touched the
+// component, and picks the highest increment type from their semver
+// labels. The increment type is empty if every pull request is labeled
+// semver:none. Pull requests without a semver label are an error.
+func analyzeLabels(ctx context.Context, client forge.Forge, comp *component.Component, latestVersion *db.Version, head string) (string, []PlanPR, error) {
+	lister, ok := client.(forge.CommitPRLister)
+	if !ok {
+		return "", nil, fmt.Errorf("--from-labels is not supported with the %s forge", client.Kind())
+	}
+
+	commits, err := releaseCommits(ctx, client, comp, latestVersion, head)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Every commit of a merged pull request maps back to it
+	var prs []forge.PullRequest
+	seen := make(map[int]bool)
+	for _, c := range commits {
+		found, err := lister.CommitPRs(ctx, c.SHA)
+		if err != nil {
+			return "", nil, err
+		}
+		for _, pr := range found {
+			if !pr.Merged || pr.Base != planBranch || seen[pr.Number] {
+				continue
+			}
+			seen[pr.Number] = true
+			prs = append(prs, pr)
+		}
+	}
+	if len(prs) == 0 {
+		return "", nil, fmt.Errorf("no pull requests were merged into '%s' for %s since the last release", planBranch, comp.Label())
+	}
+
+	rank := map[string]int{"": 0, "patch": 1, "minor": 2, "major": 3}
+	highest := ""
+	var planPRs []PlanPR
+	var unlabeled []string
+	for _, pr := range prs {
+		label := ""
+		for _, l := range pr.Labels {
+			inc, ok := semverLabels[l]
+			if ok && (label == "" || rank[inc] > rank[semverLabels[label]]) {
+				label = l
+			}
+		}
+		if label == "" {
+			unlabeled = append(unlabeled, fmt.Sprintf("#%d %s (%s)", pr.Number, pr.Title, pr.URL))
+			continue
+		}
+
+		planPRs = append(planPRs, PlanPR{Number: pr.Number, Title: pr.Title, URL: pr.URL, Label: label})
+		if inc := semverLabels[label]; rank[inc] > rank[highest] {
+			highest = inc
+		}
+	}
+
+	if len(unlabeled) > 0 {
+		return "", nil, fmt.Errorf("merged pull requests without a semver label:\n  %s\nLabel them semver:major, semver:minor, semver:patch or semver:none and plan again", strings.Join(unlabeled, "\n  "))
+	}
+
+	return highest, planPRs, nil
 }
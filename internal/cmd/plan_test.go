@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/excircle/quik-version/internal/version"
+)
+
+// setPlanFlags sets the plan flags for one test and resets them afterwards
+func setPlanFlags(t *testing.T, set func()) {
+	t.Helper()
+	reset := func() {
+		majorFlag, patchFlag, promoteFlag, autoFlag, labelsFlag = false, false, false, false, false
+		preChannel, planLine, planVersion = "", "", ""
+	}
+	reset()
+	set()
+	t.Cleanup(reset)
+}
+
+func TestValidatePlanFlagsVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		set     func()
+		wantErr string
+	}{
+		{"alone", func() {}, ""},
+		{"with --line", func() { planLine = "1.8" }, ""},
+		{"with --major", func() { majorFlag = true }, "--version cannot be combined"},
+		{"with --patch", func() { patchFlag = true }, "--version cannot be combined"},
+		{"with --pre", func() { preChannel = "rc" }, "--version cannot be combined"},
+		{"with --promote", func() { promoteFlag = true }, "--version cannot be combined"},
+		{"with --auto", func() { autoFlag = true }, "--version cannot be combined"},
+		{"with --from-labels", func() { labelsFlag = true }, "--version cannot be combined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setPlanFlags(t, func() {
+				planVersion = "1.8.4"
+				tt.set()
+			})
+			err := validatePlanFlags()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validatePlanFlags: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validatePlanFlags error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExplicitVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		current string
+		line    string
+		want    string
+		wantErr string
+	}{
+		{version: "1.3.0", current: "1.2.3", want: "1.3.0"},
+		{version: "v2.0.0-rc.1", current: "1.2.3", want: "2.0.0-rc.1"},
+		{version: "1.0.0", current: "0.0.0", want: "1.0.0"},
+		{version: "1.2.3", current: "1.2.3", wantErr: "not higher"},
+		{version: "1.2.0", current: "1.2.3", wantErr: "not higher"},
+		{version: "1.2.3-rc.1", current: "1.2.3", wantErr: "not higher"},
+		{version: "next", current: "1.2.3", wantErr: "invalid --version"},
+		{version: "1.8.4", current: "1.8.3", line: "1.8", want: "1.8.4"},
+		{version: "1.9.0", current: "1.8.3", line: "1.8", wantErr: "not in release line 1.8"},
+		{version: "1.8.4", current: "1.8.3", line: "1", wantErr: "invalid release line"},
+	}
+
+	for _, tt := range tests {
+		setPlanFlags(t, func() { planLine = tt.line })
+		got, err := explicitVersion(tt.version, tt.current)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("explicitVersion(%s, %s) line %q error = %v, want one containing %q", tt.version, tt.current, tt.line, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("explicitVersion(%s, %s) line %q: %v", tt.version, tt.current, tt.line, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("explicitVersion(%s, %s) = %s, want %s", tt.version, tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestExplicitIncrement(t *testing.T) {
+	tests := []struct {
+		current string
+		next    string
+		want    string
+	}{
+		{"1.2.3", "2.0.0", "major"},
+		{"1.2.3", "1.3.0", "minor"},
+		{"1.2.3", "1.2.4", "patch"},
+		{"1.2.3", "1.3.0-rc.1", "minor"},
+		{"1.3.0-rc.1", "1.3.0", "promote"},
+		{"1.3.0-rc.1", "1.3.0-rc.2", "minor"},
+		{"1.2.4-alpha.1", "1.2.4-beta.1", "patch"},
+		{"2.0.0-rc.1", "2.0.0-rc.2", "major"},
+		{"not-a-version", "1.0.0", "minor"},
+	}
+
+	for _, tt := range tests {
+		next, err := version.Parse(tt.next)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := explicitIncrement(tt.current, next); got != tt.want {
+			t.Errorf("explicitIncrement(%s, %s) = %q, want %q", tt.current, tt.next, got, tt.want)
+		}
+	}
+}
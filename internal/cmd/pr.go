@@ -2,31 +2,65 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"path"
+	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 
+	"github.com/excircle/quik-version/internal/changelog"
+	"github.com/excircle/quik-version/internal/component"
 	"github.com/excircle/quik-version/internal/config"
-	"github.com/excircle/quik-version/internal/github"
+	"github.com/excircle/quik-version/internal/db"
+	"github.com/excircle/quik-version/internal/forge"
 )
 
-var baseBranch string
+var (
+	baseBranch   string
+	createBranch bool
+	prLabels     []string
+	prReviewers  []string
+	prAssignees  []string
+	prDraftFlag  bool
+	prMilestone  string
+)
 
 var prCmd = &cobra.Command{
 	Use:   "pr",
 	Short: "Create a pull request with version info",
-	Long: `PR creates a GitHub pull request from the current branch to main.
+	Long: `PR creates a pull request (merge request on GitLab) from the current branch to main.
 
 This command will:
 - Read plan.yaml (fail if missing)
 - Detect current branch name
-- Authenticate to GitHub
-- Create PR with version details in title and body
-- Display PR URL`,
+- Authenticate to the forge
+- Create PR with version details in title and body, or update the open
+  PR for the same branches
+- Add labels, reviewers, assignees and the milestone
+- Display PR URL
+
+The base branch defaults to the branch in plan.yaml, or main.
+
+If a PR from the same branch to the same base is already open, for
+example after re-planning, its title and body are updated instead.
+
+Labels, reviewers, assignees, draft state and the milestone are read
+from the pr section of quik.conf, and can be overridden with --label,
+--reviewer, --assignee, --draft and --milestone. The draft state only
+applies when the PR is created.
+
+With --create-branch no local branch is needed: qv creates
+release/vX.Y.Z (release/<component>/vX.Y.Z for components) from the
+planned commit through the forge API, commits the version bump to it in
+one commit (the bump_files, or VERSION if the repository has one, and
+the changelog entry for the release) and opens the PR from that branch. Merge the PR, then
+run 'qv plan --sha <merge commit> --version <planned version>' to pin the
+merge commit without recalculating the version, and 'qv deploy' to tag it.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
 
@@ -46,16 +80,12 @@ This command will:
 			return fmt.Errorf("failed to parse plan file: %w", err)
 		}
 
-		// Get current branch name
-		branchCmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
-		branchOutput, err := branchCmd.Output()
-		if err != nil {
-			return fmt.Errorf("failed to get current branch: %w", err)
+		// Releases are merged into the branch they were planned on
+		if baseBranch == "" {
+			baseBranch = plan.Branch
 		}
-		currentBranch := strings.TrimSpace(string(branchOutput))
-
-		if currentBranch == baseBranch {
-			return fmt.Errorf("cannot create PR from %s to %s", baseBranch, baseBranch)
+		if baseBranch == "" {
+			baseBranch = "main"
 		}
 
 		// Get git URL from config
@@ -64,16 +94,32 @@ This command will:
 			return fmt.Errorf("git_url not configured. Run 'qv init' first")
 		}
 
-		// Parse owner and repo
-		owner, repo, err := github.ParseRepoURL(gitURL)
+		// Create forge client
+		client, err := newForge(ctx, gitURL)
 		if err != nil {
-			return fmt.Errorf("failed to parse git URL: %w", err)
+			return err
 		}
 
-		// Create GitHub client
-		client, err := github.NewClient(ctx)
-		if err != nil {
-			return fmt.Errorf("failed to create GitHub client: %w", err)
+		var headBranch string
+		var release *releaseCommit
+		if createBranch {
+			release, err = createReleaseBranch(ctx, client, gitURL, &plan)
+			if err != nil {
+				return err
+			}
+			headBranch = release.branch
+		} else {
+			// Get current branch name
+			branchCmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+			branchOutput, err := branchCmd.Output()
+			if err != nil {
+				return fmt.Errorf("failed to get current branch: %w", err)
+			}
+			headBranch = strings.TrimSpace(string(branchOutput))
+		}
+
+		if headBranch == baseBranch {
+			return fmt.Errorf("cannot create PR from %s to %s", baseBranch, baseBranch)
 		}
 
 		// Build PR title and body
@@ -83,31 +129,249 @@ This command will:
 **Current Version:** v%s
 **Next Version:** v%s
 **Increment Type:** %s
-
+`, plan.CurrentVersion, plan.NextVersion, plan.IncrementType)
+		if len(plan.PullRequests) > 0 {
+			body += "\nMerged pull requests:\n"
+			for _, pr := range plan.PullRequests {
+				body += fmt.Sprintf("- #%d %s (`%s`)\n", pr.Number, pr.Title, pr.Label)
+			}
+		}
+		if release != nil {
+			body += fmt.Sprintf("\n**Release Commit:** %s\n\nFiles updated:\n", release.sha)
+			for _, f := range release.files {
+				body += fmt.Sprintf("- `%s`\n", f)
+			}
+		}
+		body += `
 ---
 *Created by qv (Quik Version)*
-`, plan.CurrentVersion, plan.NextVersion, plan.IncrementType)
+`
 
-		fmt.Printf("Creating PR from '%s' to '%s'...\n", currentBranch, baseBranch)
+		opts := prOptions(cmd)
 
-		// Create PR
-		pr, err := client.CreatePR(ctx, owner, repo, title, body, currentBranch, baseBranch)
-		if err != nil {
-			return fmt.Errorf("failed to create PR: %w", err)
+		// Re-planning updates the open PR instead of creating another one
+		editor, canEdit := client.(forge.PullRequestEditor)
+		var existing *forge.PullRequest
+		if canEdit {
+			existing, err = editor.FindPR(ctx, headBranch, baseBranch)
+			if err != nil {
+				return err
+			}
+		}
+
+		var pr *forge.PullRequest
+		action := "created"
+		if existing != nil {
+			fmt.Printf("Updating PR #%d from '%s' to '%s'...\n", existing.Number, headBranch, baseBranch)
+			if err := editor.UpdatePR(ctx, existing.Number, title, body); err != nil {
+				return err
+			}
+			pr = existing
+			pr.Title = title
+			action = "updated"
+		} else {
+			fmt.Printf("Creating PR from '%s' to '%s'...\n", headBranch, baseBranch)
+			pr, err = client.CreatePR(ctx, title, body, headBranch, baseBranch, prDraft(cmd))
+			if err != nil {
+				return fmt.Errorf("failed to create PR: %w", err)
+			}
+		}
+
+		if canEdit && !opts.IsEmpty() {
+			if err := editor.SetPROptions(ctx, pr.Number, opts); err != nil {
+				return err
+			}
 		}
 
 		fmt.Println()
-		fmt.Println("Pull request created:")
+		fmt.Printf("Pull request %s:\n", action)
 		fmt.Println("---")
 		fmt.Printf("Title: %s\n", pr.Title)
 		fmt.Printf("Number: #%d\n", pr.Number)
 		fmt.Printf("URL: %s\n", pr.URL)
+		if release != nil {
+			fmt.Println()
+			fmt.Println("After merging, pin the merge commit with the planned version, then run 'qv deploy':")
+			fmt.Printf("  %s\n", replanCommand(&plan, baseBranch))
+		}
 
 		return nil
 	},
 }
 
+// replanCommand returns the 'qv plan' command that plans the same version
+// on the merge commit of a release PR
+func replanCommand(plan *PlanFile, base string) string {
+	args := []string{"qv plan", "--branch " + base, "--sha <merge commit>", "--version " + plan.NextVersion}
+	if plan.Line != "" {
+		args = append(args, "--line "+plan.Line)
+	}
+	if plan.Component != "" {
+		args = append(args, "--component "+plan.Component)
+	}
+	return strings.Join(args, " ")
+}
+
+// releaseCommit describes the release branch created by --create-branch
+type releaseCommit struct {
+	branch string
+	sha    string
+	files  []string
+}
+
+// createReleaseBranch creates the release branch for a plan from the
+// planned commit and commits the version bump files to it
+func createReleaseBranch(ctx context.Context, client forge.Forge, gitURL string, plan *PlanFile) (*releaseCommit, error) {
+	writer, ok := client.(forge.BranchWriter)
+	if !ok {
+		return nil, fmt.Errorf("--create-branch is not supported by the %s backend", client.Kind())
+	}
+
+	comp, err := component.Lookup(plan.Component)
+	if err != nil {
+		return nil, err
+	}
+	tagName := comp.TagName(plan.NextVersion)
+	branch := "release/" + tagName
+
+	// Branch from the planned commit, or the base head for older plans
+	start := plan.CommitSHA
+	if start == "" {
+		start, err = client.GetLatestCommitSHA(ctx, baseBranch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get latest commit: %w", err)
+		}
+	}
+
+	files, err := releaseFiles(ctx, client, writer, gitURL, comp, plan, start)
+	if err != nil {
+		return nil, err
+	}
+
+	// Running again after re-planning rebuilds the branch
+	fmt.Printf("Creating branch '%s' from %s...\n", branch, start[:7])
+	if err := writer.ResetBranch(ctx, branch, start); err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("Committing %d files to '%s'...\n", len(files), branch)
+	sha, err := writer.CommitChanges(ctx, branch, "chore(release): "+tagName, files)
+	if err != nil {
+		return nil, fmt.Errorf("branch %s was created, but committing the release failed: %w", branch, err)
+	}
+
+	release := &releaseCommit{branch: branch, sha: sha}
+	for _, f := range files {
+		release.files = append(release.files, f.Path)
+	}
+	return release, nil
+}
+
+// releaseFiles returns the files the release commit updates: the
+// component's bump_files (or its VERSION file if none are configured and
+// the repository has one), and the changelog with an entry for the
+// planned version
+func releaseFiles(ctx context.Context, client forge.Forge, writer forge.BranchWriter, gitURL string, comp *component.Component, plan *PlanFile, ref string) ([]forge.FileChange, error) {
+	var files []forge.FileChange
+
+	bumps, err := bumpFiles(comp.Name)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range bumps {
+		name := filepath.ToSlash(f.Path)
+		content, err := writer.GetFile(ctx, name, ref)
+		if errors.Is(err, forge.ErrFileNotFound) {
+			return nil, fmt.Errorf("bump file %s not found at %s", name, ref)
+		}
+		if err != nil {
+			return nil, err
+		}
+		updated, err := f.Apply(content, plan.NextVersion)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, forge.FileChange{Path: name, Content: updated})
+	}
+
+	// Without bump_files, a VERSION file is bumped if the repository has one
+	if len(bumps) == 0 {
+		versionPath := path.Join(filepath.ToSlash(comp.Path), "VERSION")
+		if _, err := writer.GetFile(ctx, versionPath, ref); err == nil {
+			files = append(files, forge.FileChange{Path: versionPath, Content: []byte(plan.NextVersion + "\n")})
+		} else if !errors.Is(err, forge.ErrFileNotFound) {
+			return nil, err
+		}
+	}
+
+	if !db.Exists() {
+		return nil, fmt.Errorf("database not found. Run 'qv init' first")
+	}
+	database, err := db.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	previous, err := previousRelease(database, gitURL, comp, plan.Line)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := buildChangelogEntry(ctx, client, comp, previous, &db.Version{Version: plan.NextVersion, GitSHA: ref})
+	if err != nil {
+		return nil, err
+	}
+
+	changelogName := filepath.ToSlash(changelogPath(comp))
+	existing, err := writer.GetFile(ctx, changelogName, ref)
+	if err != nil && !errors.Is(err, forge.ErrFileNotFound) {
+		return nil, err
+	}
+	files = append(files, forge.FileChange{Path: changelogName, Content: []byte(changelog.Insert(string(existing), entry))})
+
+	return files, nil
+}
+
+// prOptions returns the labels, reviewers, assignees and milestone for a
+// pull request from the pr section of quik.conf. Flags replace the
+// configured values.
+func prOptions(cmd *cobra.Command) forge.PROptions {
+	opts := forge.PROptions{
+		Labels:    config.GetPRLabels(),
+		Reviewers: config.GetPRReviewers(),
+		Assignees: config.GetPRAssignees(),
+		Milestone: config.GetPRMilestone(),
+	}
+	if cmd.Flags().Changed("label") {
+		opts.Labels = prLabels
+	}
+	if cmd.Flags().Changed("reviewer") {
+		opts.Reviewers = prReviewers
+	}
+	if cmd.Flags().Changed("assignee") {
+		opts.Assignees = prAssignees
+	}
+	if cmd.Flags().Changed("milestone") {
+		opts.Milestone = prMilestone
+	}
+	return opts
+}
+
+// prDraft reports whether a new pull request is opened as a draft
+func prDraft(cmd *cobra.Command) bool {
+	if cmd.Flags().Changed("draft") {
+		return prDraftFlag
+	}
+	return config.GetPRDraft()
+}
+
 func init() {
 	rootCmd.AddCommand(prCmd)
-	prCmd.Flags().StringVar(&baseBranch, "base", "main", "base branch for the PR")
+	prCmd.Flags().StringVar(&baseBranch, "base", "", "base branch for the PR (default is the branch in plan.yaml, or main)")
+	prCmd.Flags().BoolVar(&createBranch, "create-branch", false, "create a release branch with the version bump through the forge API")
+	prCmd.Flags().StringSliceVar(&prLabels, "label", nil, "label to add to the PR (repeatable, default pr.labels)")
+	prCmd.Flags().StringSliceVar(&prReviewers, "reviewer", nil, "user (or org/team on GitHub) to request a review from (repeatable, default pr.reviewers)")
+	prCmd.Flags().StringSliceVar(&prAssignees, "assignee", nil, "user to assign the PR to (repeatable, default pr.assignees)")
+	prCmd.Flags().BoolVar(&prDraftFlag, "draft", false, "open the PR as a draft (default pr.draft)")
+	prCmd.Flags().StringVar(&prMilestone, "milestone", "", "title of the milestone to set on the PR (default pr.milestone)")
 }
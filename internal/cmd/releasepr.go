@@ -0,0 +1,366 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/excircle/quik-version/internal/component"
+	"github.com/excircle/quik-version/internal/config"
+	"github.com/excircle/quik-version/internal/db"
+	"github.com/excircle/quik-version/internal/forge"
+	"github.com/excircle/quik-version/internal/version"
+)
+
+// defaultReleaseLabel marks the standing release PR
+const defaultReleaseLabel = "release: pending"
+
+// releasePlanMarker starts the hidden copy of the plan in a release PR body
+const releasePlanMarker = "<!-- qv-release-plan: "
+
+var (
+	releasePRBase string
+	releaseLabel  string
+)
+
+var releasePRCmd = &cobra.Command{
+	Use:   "release-pr",
+	Short: "Create or update the standing release pull request",
+	Long: `Release-pr keeps one long-lived release pull request up to date, so
+releases no longer need 'qv plan' and 'qv pr' by hand. Run it on every
+push to --branch (default main).
+
+This command will:
+- Pick the increment from Conventional Commits since the last release,
+  like 'qv plan --auto'
+- Reset the release branch (qv/release, or qv/release/<component>) to
+  the head of --branch
+- Commit the bump_files (or VERSION) and the changelog entry to it
+- Update the title and body of the open pull request carrying --label,
+  or open and label a new one (with the labels, reviewers, assignees and
+  milestone from the pr section of quik.conf)
+
+If there is nothing to release, the pull request is left as it is.
+
+The plan is recorded in the pull request body. After the pull request
+is merged, 'qv deploy --from-merged-pr' tags the merge commit with the
+recorded version.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		// Check if database exists
+		if !db.Exists() {
+			return fmt.Errorf("database not found. Run 'qv init' first")
+		}
+
+		// Get git URL from config
+		gitURL := config.GetGitURL()
+		if gitURL == "" {
+			return fmt.Errorf("git_url not configured. Run 'qv init' first")
+		}
+
+		comp, err := resolveComponent()
+		if err != nil {
+			return err
+		}
+
+		client, err := newForge(ctx, gitURL)
+		if err != nil {
+			return err
+		}
+		editor, ok := client.(forge.PullRequestEditor)
+		if !ok {
+			return fmt.Errorf("release pull requests are not supported with the %s forge", client.Kind())
+		}
+		writer, ok := client.(forge.BranchWriter)
+		if !ok {
+			return fmt.Errorf("release pull requests are not supported with the %s forge", client.Kind())
+		}
+
+		plan, err := releasePlan(ctx, client, gitURL, comp)
+		if err != nil {
+			return err
+		}
+
+		branch := releasePRBranch(comp)
+		existing, err := openReleasePR(ctx, editor, branch)
+		if err != nil {
+			return err
+		}
+
+		if plan == nil {
+			fmt.Printf("No releasable commits for %s on '%s' since the last release\n", comp.Label(), releasePRBase)
+			if existing != nil {
+				fmt.Printf("Release PR #%d left unchanged: %s\n", existing.Number, existing.URL)
+			}
+			return nil
+		}
+
+		tagName := comp.TagName(plan.NextVersion)
+		fmt.Printf("Preparing %s on '%s' from %s...\n", tagName, branch, plan.CommitSHA[:7])
+
+		// Rebuild the release branch from the base head on every run
+		if err := writer.ResetBranch(ctx, branch, plan.CommitSHA); err != nil {
+			return err
+		}
+		files, err := releaseFiles(ctx, client, writer, gitURL, comp, plan, plan.CommitSHA)
+		if err != nil {
+			return err
+		}
+		sha, err := writer.CommitChanges(ctx, branch, "chore(release): "+tagName, files)
+		if err != nil {
+			return err
+		}
+
+		title := "Release " + tagName
+		body, err := releasePRBody(plan, tagName, sha, files)
+		if err != nil {
+			return err
+		}
+
+		var pr *forge.PullRequest
+		if existing != nil {
+			if err := editor.UpdatePR(ctx, existing.Number, title, body); err != nil {
+				return err
+			}
+			pr = existing
+			fmt.Println("Release pull request updated:")
+		} else {
+			pr, err = client.CreatePR(ctx, title, body, branch, releasePRBase, false)
+			if err != nil {
+				return fmt.Errorf("failed to create PR: %w", err)
+			}
+
+			// The pr section of quik.conf applies too, except draft
+			opts := forge.PROptions{
+				Labels:    append([]string{releaseLabel}, config.GetPRLabels()...),
+				Reviewers: config.GetPRReviewers(),
+				Assignees: config.GetPRAssignees(),
+				Milestone: config.GetPRMilestone(),
+			}
+			if err := editor.SetPROptions(ctx, pr.Number, opts); err != nil {
+				return err
+			}
+			fmt.Println("Release pull request created:")
+		}
+
+		fmt.Println("---")
+		fmt.Printf("Title: %s\n", title)
+		fmt.Printf("Number: #%d\n", pr.Number)
+		fmt.Printf("URL: %s\n", pr.URL)
+		fmt.Printf("Increment Type: %s\n", plan.IncrementType)
+		fmt.Println()
+		fmt.Println("After merging, run 'qv deploy --from-merged-pr' to tag the merge commit.")
+
+		return nil
+	},
+}
+
+// releasePlan plans the next release from the Conventional Commits on the
+// base branch since the latest release, or returns nil if none of them is
+// releasable
+func releasePlan(ctx context.Context, client forge.Forge, gitURL string, comp *component.Component) (*PlanFile, error) {
+	database, err := db.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	latestVersion, err := database.GetLatestVersion(gitURL, comp.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest version: %w", err)
+	}
+	checksum, err := database.StateChecksum(gitURL, comp.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := client.GetLatestCommitSHA(ctx, releasePRBase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest commit: %w", err)
+	}
+
+	incrementType, commits, err := analyzeCommits(ctx, client, comp, latestVersion, head)
+	if err != nil {
+		return nil, err
+	}
+	if incrementType == "" {
+		return nil, nil
+	}
+
+	currentVersion := "0.0.0"
+	nextVersion := version.Initial(incrementType)
+	if latestVersion != nil {
+		currentVersion = latestVersion.Version
+		current, err := version.Parse(currentVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse current version: %w", err)
+		}
+		nextVersion = current.Next(incrementType).String()
+	}
+
+	return &PlanFile{
+		GitURL:         gitURL,
+		Component:      comp.Name,
+		CurrentVersion: currentVersion,
+		NextVersion:    nextVersion,
+		IncrementType:  incrementType,
+		Branch:         releasePRBase,
+		CommitSHA:      head,
+		DBChecksum:     checksum,
+		Commits:        commits,
+	}, nil
+}
+
+// releasePRBranch returns the head branch of a component's release PR
+func releasePRBranch(comp *component.Component) string {
+	if comp.Name == "" {
+		return "qv/release"
+	}
+	return "qv/release/" + comp.Name
+}
+
+// openReleasePR returns the open release PR from branch, or nil
+func openReleasePR(ctx context.Context, editor forge.PullRequestEditor, branch string) (*forge.PullRequest, error) {
+	prs, err := editor.ListPRs(ctx, releaseLabel, forge.PRStateOpen)
+	if err != nil {
+		return nil, err
+	}
+	for _, pr := range prs {
+		if pr.Head == branch && pr.Base == releasePRBase {
+			return &pr, nil
+		}
+	}
+	return nil, nil
+}
+
+// releasePRBody describes the pending release and records the plan in a
+// hidden comment for 'qv deploy --from-merged-pr'
+func releasePRBody(plan *PlanFile, tagName, sha string, files []forge.FileChange) (string, error) {
+	planData, err := yaml.Marshal(plan)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal plan: %w", err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Release %s\n\n", tagName)
+	fmt.Fprintf(&b, "**Current Version:** v%s\n", plan.CurrentVersion)
+	fmt.Fprintf(&b, "**Next Version:** v%s\n", plan.NextVersion)
+	fmt.Fprintf(&b, "**Increment Type:** %s\n", plan.IncrementType)
+	fmt.Fprintf(&b, "**Release Commit:** %s\n", sha)
+
+	b.WriteString("\n### Changes\n\n")
+	for _, c := range plan.Commits {
+		scope := ""
+		if c.Scope != "" {
+			scope = "(" + c.Scope + ")"
+		}
+		breaking := ""
+		if c.Breaking {
+			breaking = "!"
+		}
+		fmt.Fprintf(&b, "- %s %s%s%s: %s\n", c.SHA[:7], c.Type, scope, breaking, c.Subject)
+	}
+
+	b.WriteString("\nFiles updated:\n")
+	for _, f := range files {
+		fmt.Fprintf(&b, "- `%s`\n", f.Path)
+	}
+
+	b.WriteString(`
+This pull request is updated by 'qv release-pr' on every push. Merge it to
+release, then run 'qv deploy --from-merged-pr'.
+
+---
+*Created by qv (Quik Version)*
+`)
+	fmt.Fprintf(&b, "%s%s -->\n", releasePlanMarker, base64.StdEncoding.EncodeToString(planData))
+
+	return b.String(), nil
+}
+
+// parseReleasePlan extracts the plan recorded by releasePRBody, or
+// returns nil if body has none
+func parseReleasePlan(body string) (*PlanFile, error) {
+	_, encoded, found := strings.Cut(body, releasePlanMarker)
+	if !found {
+		return nil, nil
+	}
+	encoded, _, found = strings.Cut(encoded, " -->")
+	if !found {
+		return nil, fmt.Errorf("release plan in pull request body is truncated")
+	}
+
+	planData, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode release plan: %w", err)
+	}
+	var plan PlanFile
+	if err := yaml.Unmarshal(planData, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse release plan: %w", err)
+	}
+	return &plan, nil
+}
+
+// mergedReleasePlan finds the most recently merged release PR of a
+// component and returns its plan, pinned to the merge commit
+func mergedReleasePlan(ctx context.Context, client forge.Forge, componentName string) (*PlanFile, *forge.PullRequest, error) {
+	editor, ok := client.(forge.PullRequestEditor)
+	if !ok {
+		return nil, nil, fmt.Errorf("release pull requests are not supported with the %s forge", client.Kind())
+	}
+
+	prs, err := editor.ListPRs(ctx, releaseLabel, forge.PRStateMerged)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, pr := range prs {
+		plan, err := parseReleasePlan(pr.Body)
+		if err != nil {
+			return nil, nil, fmt.Errorf("PR #%d: %w", pr.Number, err)
+		}
+		if plan == nil || plan.Component != componentName {
+			continue
+		}
+		if pr.MergeCommitSHA == "" {
+			return nil, nil, fmt.Errorf("PR #%d has no merge commit", pr.Number)
+		}
+
+		plan.CommitSHA = pr.MergeCommitSHA
+		plan.Ref = ""
+		return plan, &pr, nil
+	}
+
+	return nil, nil, fmt.Errorf("no merged pull request labeled %q with a release plan found. Run 'qv release-pr' first", releaseLabel)
+}
+
+// isReleased reports whether a plan's version is already recorded in qv.db
+func isReleased(gitURL string, plan *PlanFile) (bool, error) {
+	database, err := db.Open()
+	if err != nil {
+		return false, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	versions, err := database.GetAllVersions(gitURL, plan.Component)
+	if err != nil {
+		return false, err
+	}
+	for _, v := range versions {
+		if v.Version == plan.NextVersion {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func init() {
+	rootCmd.AddCommand(releasePRCmd)
+	releasePRCmd.Flags().StringVar(&releasePRBase, "branch", "main", "branch to release from and merge the release PR into")
+	releasePRCmd.Flags().StringVar(&releaseLabel, "label", defaultReleaseLabel, "label that marks the release PR")
+	addComponentFlag(releasePRCmd)
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+
+	"github.com/excircle/quik-version/internal/prompt"
 )
 
 var configFile string
@@ -14,7 +16,7 @@ var rootCmd = &cobra.Command{
 	Use:   "qv",
 	Short: "Quik Version - A tool for semantic versioning and container builds",
 	Long: `Quik Version (qv) is a CLI tool that manages semantic versioning
-and container builds for GitHub repositories.
+and container builds for GitHub, GitLab and Gitea repositories.
 
 It automates the process of version bumping, git tagging, and
 optionally building containers using buildah.`,
@@ -31,6 +33,8 @@ func init() {
 	cobra.OnInitialize(initConfig)
 
 	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "config file (default is ./quik.conf)")
+	rootCmd.PersistentFlags().BoolVarP(&prompt.AssumeYes, "yes", "y", false, "answer yes to every confirmation and never prompt")
+	rootCmd.PersistentFlags().BoolVar(&prompt.NonInteractive, "non-interactive", false, "never prompt; fail if input is required")
 }
 
 func initConfig() {
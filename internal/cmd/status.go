@@ -16,10 +16,90 @@ const planFileName = "plan.yaml"
 
 // PlanFile represents the structure of plan.yaml
 type PlanFile struct {
-	GitURL         string `yaml:"git_url"`
-	CurrentVersion string `yaml:"current_version"`
-	NextVersion    string `yaml:"next_version"`
-	IncrementType  string `yaml:"increment_type"`
+	GitURL         string       `yaml:"git_url" json:"git_url"`
+	Component      string       `yaml:"component,omitempty" json:"component,omitempty"`
+	CurrentVersion string       `yaml:"current_version" json:"current_version"`
+	NextVersion    string       `yaml:"next_version" json:"next_version"`
+	IncrementType  string       `yaml:"increment_type" json:"increment_type"`
+	Channel        string       `yaml:"channel,omitempty" json:"channel,omitempty"`
+	Line           string       `yaml:"line,omitempty" json:"line,omitempty"`
+	Branch         string       `yaml:"branch,omitempty" json:"branch,omitempty"`
+	Ref            string       `yaml:"ref,omitempty" json:"ref,omitempty"`
+	CommitSHA      string       `yaml:"commit_sha,omitempty" json:"commit_sha,omitempty"`
+	DBChecksum     string       `yaml:"db_checksum,omitempty" json:"db_checksum,omitempty"`
+	Commits        []PlanCommit `yaml:"commits,omitempty" json:"commits,omitempty"`
+	PullRequests   []PlanPR     `yaml:"pull_requests,omitempty" json:"pull_requests,omitempty"`
+}
+
+// PlanCommit records a commit that contributed to the planned increment
+type PlanCommit struct {
+	SHA      string `yaml:"sha" json:"sha"`
+	Type     string `yaml:"type" json:"type"`
+	Scope    string `yaml:"scope,omitempty" json:"scope,omitempty"`
+	Subject  string `yaml:"subject" json:"subject"`
+	Breaking bool   `yaml:"breaking,omitempty" json:"breaking,omitempty"`
+}
+
+// PlanPR records a merged pull request and the semver label that
+// contributed to the planned increment
+type PlanPR struct {
+	Number int    `yaml:"number" json:"number"`
+	Title  string `yaml:"title" json:"title"`
+	URL    string `yaml:"url" json:"url"`
+	Label  string `yaml:"label" json:"label"`
+}
+
+// StatusOutput is the machine-readable result of qv status
+type StatusOutput struct {
+	Repository string            `yaml:"repository" json:"repository"`
+	Component  string            `yaml:"component,omitempty" json:"component,omitempty"`
+	Current    *VersionStatus    `yaml:"current" json:"current"`
+	Next       NextVersions      `yaml:"next" json:"next"`
+	Components []ComponentStatus `yaml:"components,omitempty" json:"components,omitempty"`
+	Lines      []LineStatus      `yaml:"lines,omitempty" json:"lines,omitempty"`
+	Plan       *PlanFile         `yaml:"plan" json:"plan"`
+}
+
+// VersionStatus describes the latest recorded version
+type VersionStatus struct {
+	Version    string        `yaml:"version" json:"version"`
+	Tag        string        `yaml:"tag" json:"tag"`
+	CommitSHA  string        `yaml:"commit_sha" json:"commit_sha"`
+	CreatedAt  string        `yaml:"created_at" json:"created_at"`
+	ReleaseURL string        `yaml:"release_url,omitempty" json:"release_url,omitempty"`
+	Images     []ImageStatus `yaml:"images,omitempty" json:"images,omitempty"`
+}
+
+// ImageStatus describes an image built for a version
+type ImageStatus struct {
+	Image        string `yaml:"image" json:"image"`
+	Digest       string `yaml:"digest" json:"digest"`
+	PushedDigest string `yaml:"pushed_digest,omitempty" json:"pushed_digest,omitempty"`
+}
+
+// NextVersions lists the version each kind of plan would create
+type NextVersions struct {
+	Major      string `yaml:"major" json:"major"`
+	Minor      string `yaml:"minor" json:"minor"`
+	Patch      string `yaml:"patch" json:"patch"`
+	Channel    string `yaml:"channel" json:"channel"`
+	PreRelease string `yaml:"pre_release" json:"pre_release"`
+	Promote    string `yaml:"promote,omitempty" json:"promote,omitempty"`
+}
+
+// ComponentStatus describes the latest version of a monorepo component
+type ComponentStatus struct {
+	Name    string `yaml:"name" json:"name"`
+	Version string `yaml:"version,omitempty" json:"version,omitempty"`
+	Tag     string `yaml:"tag,omitempty" json:"tag,omitempty"`
+}
+
+// LineStatus describes the latest version of a maintained release line
+type LineStatus struct {
+	Line      string `yaml:"line" json:"line"`
+	Branch    string `yaml:"branch" json:"branch"`
+	Latest    string `yaml:"latest,omitempty" json:"latest,omitempty"`
+	NextPatch string `yaml:"next_patch,omitempty" json:"next_patch,omitempty"`
 }
 
 var statusCmd = &cobra.Command{
@@ -31,7 +111,9 @@ This command will:
 - Query qv.db for latest version record
 - Display current version, last tag, commit SHA, timestamp
 - Show pending changes if plan.yaml exists
-- Show what would be created if a plan were run`,
+- Show what would be created if a plan were run
+
+Use --output json or --output yaml for a machine-readable report.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Check if database exists
 		if !db.Exists() {
@@ -51,46 +133,111 @@ This command will:
 			return fmt.Errorf("git_url not configured. Run 'qv init' first")
 		}
 
-		fmt.Printf("Repository: %s\n", gitURL)
-		fmt.Println("---")
+		// Resolve the component to report on
+		comp, err := resolveComponent()
+		if err != nil {
+			return err
+		}
+
+		status := StatusOutput{Repository: gitURL, Component: comp.Name}
 
 		// Get latest version
-		latestVersion, err := database.GetLatestVersion(gitURL)
+		latestVersion, err := database.GetLatestVersion(gitURL, comp.Name)
 		if err != nil {
 			return fmt.Errorf("failed to get latest version: %w", err)
 		}
 
 		if latestVersion == nil {
-			fmt.Println("No versions recorded yet.")
-			fmt.Println()
-			fmt.Println("Next version (if plan is run):")
-			fmt.Println("  Minor: v0.1.0")
-			fmt.Println("  Major: v1.0.0")
-			fmt.Println("  Patch: v0.0.1")
+			status.Next = NextVersions{
+				Major:      version.Initial("major"),
+				Minor:      version.Initial("minor"),
+				Patch:      version.Initial("patch"),
+				Channel:    "rc",
+				PreRelease: version.InitialPreRelease("minor", "rc"),
+			}
 		} else {
-			fmt.Printf("Current Version: %s\n", latestVersion.Version)
-			fmt.Printf("Tag: %s\n", latestVersion.TagName)
-			fmt.Printf("Commit SHA: %s\n", latestVersion.GitSHA)
-			fmt.Printf("Created: %s\n", latestVersion.CreatedAt)
+			current := &VersionStatus{
+				Version:   latestVersion.Version,
+				Tag:       latestVersion.TagName,
+				CommitSHA: latestVersion.GitSHA,
+				CreatedAt: latestVersion.CreatedAt,
+			}
+			if latestVersion.ReleaseURL != nil {
+				current.ReleaseURL = *latestVersion.ReleaseURL
+			}
+
+			images, err := database.GetImages(gitURL, comp.Name, latestVersion.Version)
+			if err != nil {
+				return fmt.Errorf("failed to get images: %w", err)
+			}
+			for _, img := range images {
+				image := ImageStatus{Image: img.Image, Digest: img.Digest}
+				if img.PushedDigest != nil {
+					image.PushedDigest = *img.PushedDigest
+				}
+				current.Images = append(current.Images, image)
+			}
+			status.Current = current
+
+			// Work out what next versions would be
+			parsed, err := version.Parse(latestVersion.Version)
+			if err != nil {
+				return fmt.Errorf("failed to parse current version: %w", err)
+			}
+			status.Next = NextVersions{
+				Major:   parsed.NextMajor().String(),
+				Minor:   parsed.NextMinor().String(),
+				Patch:   parsed.NextPatch().String(),
+				Channel: "rc",
+			}
+			if parsed.IsPreRelease() {
+				status.Next.Channel = parsed.Channel()
+				status.Next.Promote = parsed.Core().String()
+			}
+			status.Next.PreRelease = parsed.NextPreRelease("", status.Next.Channel).String()
+		}
 
-			// Show what next versions would be
-			nextMajor := version.IncrementMajor(latestVersion.Version)
-			nextMinor := version.IncrementMinor(latestVersion.Version)
-			nextPatch := version.IncrementPatch(latestVersion.Version)
+		// Summarize every component when no component was selected
+		if comp.Name == "" {
+			for _, c := range config.GetComponents() {
+				latest, err := database.GetLatestVersion(gitURL, c.Name)
+				if err != nil {
+					return fmt.Errorf("failed to get latest version for %s: %w", c.Name, err)
+				}
+				cs := ComponentStatus{Name: c.Name}
+				if latest != nil {
+					cs.Version = latest.Version
+					cs.Tag = latest.TagName
+				}
+				status.Components = append(status.Components, cs)
+			}
+		}
 
-			fmt.Println()
-			fmt.Println("Next version (if plan is run):")
-			fmt.Printf("  Minor (default): v%s\n", nextMinor)
-			fmt.Printf("  Major (--major): v%s\n", nextMajor)
-			fmt.Printf("  Patch (--patch): v%s\n", nextPatch)
+		// Find the latest version of every maintained release line
+		for _, l := range config.GetLines() {
+			line, err := version.ParseLine(l.Line)
+			if err != nil {
+				return err
+			}
+			ls := LineStatus{Line: line.String(), Branch: config.GetLineBranch(line.String())}
+
+			latest, err := database.GetLatestVersionInLine(gitURL, comp.Name, line)
+			if err != nil {
+				return fmt.Errorf("failed to get latest version for line %s: %w", line, err)
+			}
+			if latest != nil {
+				parsed, err := version.Parse(latest.Version)
+				if err != nil {
+					return fmt.Errorf("failed to parse version %s: %w", latest.Version, err)
+				}
+				ls.Latest = latest.Version
+				ls.NextPatch = parsed.NextPatch().String()
+			}
+			status.Lines = append(status.Lines, ls)
 		}
 
 		// Check for pending plan
 		if _, err := os.Stat(planFileName); err == nil {
-			fmt.Println()
-			fmt.Println("---")
-			fmt.Println("PENDING PLAN:")
-
 			planData, err := os.ReadFile(planFileName)
 			if err != nil {
 				return fmt.Errorf("failed to read plan file: %w", err)
@@ -100,18 +247,113 @@ This command will:
 			if err := yaml.Unmarshal(planData, &plan); err != nil {
 				return fmt.Errorf("failed to parse plan file: %w", err)
 			}
-
-			fmt.Printf("  Current: %s\n", plan.CurrentVersion)
-			fmt.Printf("  Next: %s\n", plan.NextVersion)
-			fmt.Printf("  Type: %s\n", plan.IncrementType)
-			fmt.Println()
-			fmt.Println("Run 'qv deploy' to apply this plan.")
+			status.Plan = &plan
 		}
 
+		if machineOutput() {
+			return writeOutput(status)
+		}
+		printStatus(&status)
 		return nil
 	},
 }
 
+// printStatus prints the status report as text
+func printStatus(status *StatusOutput) {
+	fmt.Printf("Repository: %s\n", status.Repository)
+	if status.Component != "" {
+		fmt.Printf("Component: %s\n", status.Component)
+	}
+	fmt.Println("---")
+
+	next := status.Next
+	if current := status.Current; current == nil {
+		fmt.Println("No versions recorded yet.")
+		fmt.Println()
+		fmt.Println("Next version (if plan is run):")
+		fmt.Printf("  Minor: v%s\n", next.Minor)
+		fmt.Printf("  Major: v%s\n", next.Major)
+		fmt.Printf("  Patch: v%s\n", next.Patch)
+		fmt.Printf("  Pre-release (--pre rc): v%s\n", next.PreRelease)
+	} else {
+		fmt.Printf("Current Version: %s\n", current.Version)
+		fmt.Printf("Tag: %s\n", current.Tag)
+		fmt.Printf("Commit SHA: %s\n", current.CommitSHA)
+		fmt.Printf("Created: %s\n", current.CreatedAt)
+		if current.ReleaseURL != "" {
+			fmt.Printf("Release: %s\n", current.ReleaseURL)
+		}
+		for _, img := range current.Images {
+			if img.PushedDigest != "" {
+				fmt.Printf("Image: %s@%s (pushed)\n", img.Image, img.PushedDigest)
+			} else {
+				fmt.Printf("Image: %s (local %s)\n", img.Image, img.Digest)
+			}
+		}
+
+		fmt.Println()
+		fmt.Println("Next version (if plan is run):")
+		fmt.Printf("  Minor (default): v%s\n", next.Minor)
+		fmt.Printf("  Major (--major): v%s\n", next.Major)
+		fmt.Printf("  Patch (--patch): v%s\n", next.Patch)
+		if next.Promote != "" {
+			fmt.Printf("  Next %s (--pre %s): v%s\n", next.Channel, next.Channel, next.PreRelease)
+			fmt.Printf("  Promote (--promote): v%s\n", next.Promote)
+		} else {
+			fmt.Printf("  Pre-release (--pre rc): v%s\n", next.PreRelease)
+		}
+	}
+
+	if len(status.Components) > 0 {
+		fmt.Println()
+		fmt.Println("Components:")
+		for _, c := range status.Components {
+			if c.Version == "" {
+				fmt.Printf("  %s: no versions recorded\n", c.Name)
+			} else {
+				fmt.Printf("  %s: %s (%s)\n", c.Name, c.Version, c.Tag)
+			}
+		}
+	}
+
+	if len(status.Lines) > 0 {
+		fmt.Println()
+		fmt.Println("Release lines:")
+		for _, l := range status.Lines {
+			if l.Latest == "" {
+				fmt.Printf("  %s (%s): no versions recorded\n", l.Line, l.Branch)
+			} else {
+				fmt.Printf("  %s (%s): %s, next patch v%s (--line %s)\n", l.Line, l.Branch, l.Latest, l.NextPatch, l.Line)
+			}
+		}
+	}
+
+	if plan := status.Plan; plan != nil {
+		fmt.Println()
+		fmt.Println("---")
+		fmt.Println("PENDING PLAN:")
+		if plan.Component != "" {
+			fmt.Printf("  Component: %s\n", plan.Component)
+		}
+		fmt.Printf("  Current: %s\n", plan.CurrentVersion)
+		fmt.Printf("  Next: %s\n", plan.NextVersion)
+		fmt.Printf("  Type: %s\n", plan.IncrementType)
+		if plan.Channel != "" {
+			fmt.Printf("  Channel: %s\n", plan.Channel)
+		}
+		if plan.Line != "" {
+			fmt.Printf("  Release Line: %s\n", plan.Line)
+		}
+		if plan.CommitSHA != "" {
+			fmt.Printf("  Commit: %s (%s)\n", plan.CommitSHA, plan.Branch)
+		}
+		fmt.Println()
+		fmt.Println("Run 'qv deploy' to apply this plan.")
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(statusCmd)
+	addComponentFlag(statusCmd)
+	addOutputFlag(statusCmd)
 }
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/spf13/cobra"
+
+	"github.com/excircle/quik-version/internal/component"
+	"github.com/excircle/quik-version/internal/db"
+	"github.com/excircle/quik-version/internal/forge"
+	"github.com/excircle/quik-version/internal/version"
+)
+
+var (
+	targetSHA string
+	targetRef string
+)
+
+// shaPattern matches a full or abbreviated commit SHA
+var shaPattern = regexp.MustCompile(`^[0-9a-fA-F]{7,40}$`)
+
+// addTargetFlags registers --sha and --ref on a command
+func addTargetFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&targetSHA, "sha", "", "commit SHA to release instead of the branch head")
+	cmd.Flags().StringVar(&targetRef, "ref", "", "branch or tag whose commit is released instead of the branch head")
+	cmd.MarkFlagsMutuallyExclusive("sha", "ref")
+}
+
+// explicitTarget returns the commit selected with --sha or --ref, or an
+// empty string if the branch head should be used
+func explicitTarget() (string, error) {
+	if targetSHA != "" {
+		if !shaPattern.MatchString(targetSHA) {
+			return "", fmt.Errorf("invalid commit SHA %q", targetSHA)
+		}
+		return targetSHA, nil
+	}
+	return targetRef, nil
+}
+
+// resolveTarget resolves ref to a commit on the remote and checks that the
+// commit is reachable from branch
+func resolveTarget(ctx context.Context, client forge.Forge, ref, branch string) (string, error) {
+	sha, err := client.ResolveCommit(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+
+	// Commits reachable from sha but not branch mean sha is not on branch
+	commits, err := client.CompareCommits(ctx, branch, sha)
+	if err != nil {
+		return "", fmt.Errorf("failed to compare %s with '%s': %w", ref, branch, err)
+	}
+	if len(commits) > 0 {
+		return "", fmt.Errorf("commit %s is not reachable from branch '%s'", sha[:7], branch)
+	}
+
+	return sha, nil
+}
+
+// checkDescendantVersions refuses to release nextVersion on sha when a
+// version with higher precedence is already tagged on sha or a commit that
+// descends from it, so versions never decrease along the history
+func checkDescendantVersions(ctx context.Context, client forge.Forge, database *db.DB, gitURL string, comp *component.Component, nextVersion, sha string) error {
+	next, err := version.Parse(nextVersion)
+	if err != nil {
+		return fmt.Errorf("invalid next version: %w", err)
+	}
+
+	versions, err := database.GetVersionHistory(gitURL, comp.Name)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range versions {
+		parsed, err := version.Parse(v.Version)
+		if err != nil || !next.LessThan(parsed) {
+			continue
+		}
+
+		// sha is an ancestor of the tagged commit if the tagged commit
+		// contains every commit reachable from sha
+		commits, err := client.CompareCommits(ctx, v.GitSHA, sha)
+		if err != nil {
+			return fmt.Errorf("failed to compare %s with %s: %w", sha[:7], v.TagName, err)
+		}
+		if len(commits) == 0 {
+			return fmt.Errorf("cannot release v%s on %s: %s is already tagged on descendant commit %s", nextVersion, sha[:7], v.TagName, v.GitSHA[:7])
+		}
+	}
+
+	return nil
+}
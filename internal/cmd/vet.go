@@ -1,33 +1,81 @@
 package cmd
 
 import (
-	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 
+	"github.com/excircle/quik-version/internal/component"
 	"github.com/excircle/quik-version/internal/config"
 	"github.com/excircle/quik-version/internal/db"
-	"github.com/excircle/quik-version/internal/github"
+	"github.com/excircle/quik-version/internal/forge"
+	"github.com/excircle/quik-version/internal/prompt"
+	"github.com/excircle/quik-version/internal/version"
+)
+
+// Reconciliation strategies for qv vet
+const (
+	strategyRemoteWins  = "remote-wins"
+	strategyLocalWins   = "local-wins"
+	strategyInteractive = "interactive"
+)
+
+var (
+	vetStrategy string
+	vetDryRun   bool
+	vetAudit    bool
+)
+
+// vetConfirm and vetAsk ask the interactive reconciliation questions
+var (
+	vetConfirm = prompt.Confirm
+	vetAsk     = prompt.String
 )
 
 var vetCmd = &cobra.Command{
 	Use:   "vet",
 	Short: "Validate git tags against local database",
 	Long: `Vet checks quik.conf for git_url and validates that
-local qv.db matches the remote GitHub tags.
+local qv.db matches the remote tags on the forge.
 
 This command will:
 - Load config and validate git_url exists
-- Fetch latest tags from GitHub
+- Fetch latest tags from the forge
 - Compare with local qv.db
-- Report discrepancies and offer reconciliation options`,
+- Report discrepancies and offer reconciliation options
+
+--strategy decides how discrepancies are reconciled:
+- remote-wins: import remote-only tags into qv.db, delete versions that
+  only exist in qv.db, and point mismatched versions at the remote commit
+- local-wins: recreate missing remote tags from qv.db and move mismatched
+  remote tags to the commit in qv.db (remote-only tags are left alone)
+- interactive (default): ask what to do about each discrepancy
+
+The reconciliation plan is printed before anything changes; --dry-run
+stops there. remote-wins and local-wins ask for confirmation unless --yes
+is given. Without a terminal (or with --non-interactive) the interactive
+strategy skips reconciliation. Every action is recorded in qv.db; use
+--audit to list them.
+
+Use --output json or --output yaml for a machine-readable report.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
 
+		switch vetStrategy {
+		case strategyRemoteWins, strategyLocalWins, strategyInteractive:
+		default:
+			return fmt.Errorf("invalid strategy %q (must be remote-wins, local-wins or interactive)", vetStrategy)
+		}
+		if vetDryRun && vetStrategy == strategyInteractive {
+			return fmt.Errorf("--dry-run needs --strategy remote-wins or local-wins")
+		}
+
 		// Check if database exists
 		if !db.Exists() {
 			return fmt.Errorf("database not found. Run 'qv init' first")
@@ -39,136 +87,567 @@ This command will:
 			return fmt.Errorf("git_url not configured. Run 'qv init' first")
 		}
 
-		// Parse owner and repo from URL
-		owner, repo, err := github.ParseRepoURL(gitURL)
+		// Resolve the component whose tags are checked
+		comp, err := resolveComponent()
 		if err != nil {
-			return fmt.Errorf("failed to parse git URL: %w", err)
+			return err
 		}
 
-		fmt.Printf("Repository: %s/%s\n", owner, repo)
-		fmt.Println("---")
-
-		// Create GitHub client
-		client, err := github.NewClient(ctx)
+		// Open database
+		database, err := db.Open()
 		if err != nil {
-			return fmt.Errorf("failed to create GitHub client: %w", err)
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer database.Close()
+
+		if vetAudit {
+			return printAuditTrail(database, gitURL, comp.Name)
 		}
 
-		// Fetch remote tags
-		fmt.Println("Fetching remote tags...")
-		remoteTags, err := client.ListTags(ctx, owner, repo)
+		// Create forge client
+		client, err := newForge(ctx, gitURL)
 		if err != nil {
-			return fmt.Errorf("failed to fetch remote tags: %w", err)
+			return err
 		}
 
-		// Open database
-		database, err := db.Open()
+		printf("Repository: %s\n", client.RepoPath())
+		printLine("---")
+		if comp.Name != "" {
+			printf("Component: %s\n", comp.Name)
+		}
+
+		// Fetch remote tags
+		printLine("Fetching remote tags...")
+		allTags, err := client.ListTags(ctx)
 		if err != nil {
-			return fmt.Errorf("failed to open database: %w", err)
+			return fmt.Errorf("failed to fetch remote tags: %w", err)
 		}
-		defer database.Close()
 
 		// Get local versions
-		localVersions, err := database.GetAllVersions(gitURL)
+		localVersions, err := database.GetAllVersions(gitURL, comp.Name)
 		if err != nil {
 			return fmt.Errorf("failed to get local versions: %w", err)
 		}
 
-		// Build maps for comparison
-		remoteTagMap := make(map[string]string) // tag name -> SHA
-		for _, tag := range remoteTags {
-			remoteTagMap[tag.Name] = tag.SHA
-		}
+		diff := newTagDiff(comp, allTags, localVersions)
 
-		localTagMap := make(map[string]string) // tag name -> SHA
-		for _, v := range localVersions {
-			localTagMap[v.TagName] = v.GitSHA
+		result := VetOutput{
+			Repository:      client.RepoPath(),
+			Component:       comp.Name,
+			Strategy:        vetStrategy,
+			DryRun:          vetDryRun,
+			RemoteTags:      len(diff.remote),
+			IgnoredTags:     diff.ignoredTags,
+			LocalVersions:   len(diff.local),
+			IgnoredVersions: diff.ignoredVersions,
+			RemoteOnly:      diff.remoteOnly,
+			LocalOnly:       diff.localOnly,
+			Mismatched:      diff.mismatched,
+			Actions:         []VetAction{},
+			Synced:          []string{},
 		}
 
-		// Find discrepancies
-		var remoteOnly []string
-		var localOnly []string
-		var mismatched []string
+		// Report findings
+		printf("\nRemote tags: %d\n", len(diff.remote))
+		if result.IgnoredTags > 0 {
+			printf("Ignored tags not matching %q: %d\n", comp.TagTemplate, result.IgnoredTags)
+		}
+		printf("Local versions: %d\n", len(diff.local))
+		if result.IgnoredVersions > 0 {
+			printf("Ignored versions in qv.db not matching %q: %d\n", comp.TagTemplate, result.IgnoredVersions)
+		}
 
-		for tagName, remoteSHA := range remoteTagMap {
-			if localSHA, exists := localTagMap[tagName]; !exists {
-				remoteOnly = append(remoteOnly, tagName)
-			} else if localSHA != remoteSHA {
-				mismatched = append(mismatched, tagName)
+		// Report which versions have pushed images
+		if config.GetBuildManagement() {
+			imageVersions, err := database.GetImageVersions(gitURL, comp.Name)
+			if err != nil {
+				return fmt.Errorf("failed to get images: %w", err)
+			}
+			printf("Versions with pushed images: %d\n", len(imageVersions))
+			for _, v := range localVersions {
+				if _, ok := diff.local[v.TagName]; !ok {
+					continue
+				}
+				if _, ok := imageVersions[v.Version]; !ok {
+					printf("  (no image) %s\n", v.TagName)
+					result.MissingImages = append(result.MissingImages, v.TagName)
+				}
 			}
 		}
+		printLine()
 
-		for tagName := range localTagMap {
-			if _, exists := remoteTagMap[tagName]; !exists {
-				localOnly = append(localOnly, tagName)
+		if len(diff.remoteOnly) == 0 && len(diff.localOnly) == 0 && len(diff.mismatched) == 0 {
+			printLine("✓ Database is in sync with remote.")
+			result.InSync = true
+			return finishVet(&result)
+		}
+
+		if len(diff.remoteOnly) > 0 {
+			printf("Tags on remote but not in local DB (%d):\n", len(diff.remoteOnly))
+			for _, tag := range diff.remoteOnly {
+				printf("  + %s\n", tag)
 			}
+			printLine()
 		}
 
-		// Report findings
-		fmt.Printf("\nRemote tags: %d\n", len(remoteTags))
-		fmt.Printf("Local versions: %d\n", len(localVersions))
-		fmt.Println()
+		if len(diff.localOnly) > 0 {
+			printf("Tags in local DB but not on remote (%d):\n", len(diff.localOnly))
+			for _, tag := range diff.localOnly {
+				printf("  - %s\n", tag)
+			}
+			printLine()
+		}
 
-		if len(remoteOnly) == 0 && len(localOnly) == 0 && len(mismatched) == 0 {
-			fmt.Println("✓ Database is in sync with remote.")
-			return nil
+		if len(diff.mismatched) > 0 {
+			printf("Tags with mismatched SHAs (%d):\n", len(diff.mismatched))
+			for _, tag := range diff.mismatched {
+				printf("  ! %s (local %s, remote %s)\n", tag, shortSHA(diff.local[tag]), shortSHA(diff.remote[tag]))
+			}
+			printLine()
 		}
 
-		if len(remoteOnly) > 0 {
-			fmt.Printf("Tags on remote but not in local DB (%d):\n", len(remoteOnly))
-			for _, tag := range remoteOnly {
-				fmt.Printf("  + %s\n", tag)
+		// Decide how to reconcile
+		var actions []VetAction
+		if vetStrategy == strategyInteractive {
+			actions, err = askReconciliation(diff)
+			if errors.Is(err, prompt.ErrNoInput) {
+				if len(actions) == 0 {
+					printLine("Reconciliation skipped: not running interactively. Re-run with --yes to import remote tags, or choose a --strategy.")
+					return finishVet(&result)
+				}
+				printLine("Tags that need a choice were skipped: not running interactively. Use --strategy remote-wins or local-wins.")
+			} else if err != nil {
+				return err
 			}
-			fmt.Println()
+		} else {
+			actions = strategyActions(vetStrategy, diff)
+			if vetStrategy == strategyLocalWins && len(diff.remoteOnly) > 0 {
+				printf("Leaving %d remote-only tags alone: local-wins never deletes tags qv.db has no record of.\n", len(diff.remoteOnly))
+			}
+		}
+
+		if len(actions) == 0 {
+			printLine("Nothing to reconcile.")
+			return finishVet(&result)
+		}
+
+		printf("Reconciliation plan (%s):\n", vetStrategy)
+		for _, a := range actions {
+			printf("  %s\n", describeAction(a))
 		}
+		printLine()
 
-		if len(localOnly) > 0 {
-			fmt.Printf("Tags in local DB but not on remote (%d):\n", len(localOnly))
-			for _, tag := range localOnly {
-				fmt.Printf("  - %s\n", tag)
+		if vetDryRun {
+			for _, a := range actions {
+				a.Status = actionPlanned
+				result.Actions = append(result.Actions, a)
 			}
-			fmt.Println()
+			printLine("Dry run: no changes were made.")
+			return finishVet(&result)
 		}
 
-		if len(mismatched) > 0 {
-			fmt.Printf("Tags with mismatched SHAs (%d):\n", len(mismatched))
-			for _, tag := range mismatched {
-				fmt.Printf("  ! %s\n", tag)
+		// The interactive answers already confirmed each action
+		if vetStrategy != strategyInteractive {
+			confirmed, err := vetConfirm(fmt.Sprintf("Apply %d reconciliation actions?", len(actions)))
+			if errors.Is(err, prompt.ErrNoInput) {
+				return fmt.Errorf("reconciliation needs confirmation; rerun with --yes to apply the plan")
+			}
+			if err != nil {
+				return err
 			}
-			fmt.Println()
-		}
-
-		// Offer reconciliation
-		if len(remoteOnly) > 0 {
-			fmt.Print("Sync remote tags to local DB? (y/n): ")
-			reader := bufio.NewReader(os.Stdin)
-			response, _ := reader.ReadString('\n')
-			response = strings.TrimSpace(strings.ToLower(response))
-
-			if response == "y" || response == "yes" {
-				for _, tagName := range remoteOnly {
-					sha := remoteTagMap[tagName]
-					version := strings.TrimPrefix(tagName, "v")
-					err := database.InsertVersion(&db.Version{
-						Version: version,
-						TagName: tagName,
-						GitSHA:  sha,
-						GitURL:  gitURL,
-					})
-					if err != nil {
-						fmt.Printf("  Failed to add %s: %v\n", tagName, err)
-					} else {
-						fmt.Printf("  Added %s\n", tagName)
-					}
+			if !confirmed {
+				printLine("Reconciliation cancelled.")
+				return finishVet(&result)
+			}
+		}
+
+		audit := db.Reconciliation{
+			GitURL:    gitURL,
+			Component: comp.Name,
+			Strategy:  vetStrategy,
+		}
+		actor := currentActor(ctx, client)
+		audit.Actor = &actor
+		if hostname, err := os.Hostname(); err == nil {
+			audit.Hostname = &hostname
+		}
+
+		failed := 0
+		for _, a := range actions {
+			applyErr := applyAction(ctx, client, database, gitURL, comp, diff.versions[a.Tag], a)
+
+			entry := audit
+			entry.TagName = a.Tag
+			entry.Action = a.Action
+			entry.OldSHA = optional(a.OldSHA)
+			entry.NewSHA = optional(a.NewSHA)
+			a.Status = actionDone
+			if applyErr != nil {
+				text := applyErr.Error()
+				entry.Error = &text
+				a.Status = actionFailed
+				a.Error = text
+				failed++
+				printf("  Failed to %s: %v\n", describeAction(a), applyErr)
+			} else {
+				printf("  Done: %s\n", describeAction(a))
+				if a.Action == db.ActionImport {
+					result.Synced = append(result.Synced, a.Tag)
 				}
-				fmt.Println("Sync complete.")
 			}
+			if err := database.RecordReconciliation(&entry); err != nil {
+				printf("  Warning: %v\n", err)
+			}
+			result.Actions = append(result.Actions, a)
 		}
 
-		return nil
+		if failed > 0 {
+			if err := finishVet(&result); err != nil {
+				return err
+			}
+			return fmt.Errorf("%d of %d reconciliation actions failed", failed, len(actions))
+		}
+		printLine("Reconciliation complete.")
+
+		return finishVet(&result)
 	},
 }
 
+// tagDiff holds the tags of a component on the remote and in qv.db, and
+// how they differ
+type tagDiff struct {
+	remote     map[string]string // tag name -> SHA
+	local      map[string]string // tag name -> SHA
+	versions   map[string]string // remote tag name -> version
+	remoteOnly []string
+	localOnly  []string
+	mismatched []string
+
+	// Tags and versions left out for not matching the tag template
+	ignoredTags     int
+	ignoredVersions int
+}
+
+// newTagDiff compares the remote tags with the versions in qv.db. Only tag
+// names matching the component's tag template are compared, on both sides,
+// so rows recorded under another template are not reported as local-only.
+func newTagDiff(comp *component.Component, tags []forge.Tag, versions []db.Version) *tagDiff {
+	diff := &tagDiff{
+		remote:     make(map[string]string),
+		local:      make(map[string]string),
+		versions:   make(map[string]string),
+		remoteOnly: []string{},
+		localOnly:  []string{},
+		mismatched: []string{},
+	}
+
+	for _, tag := range tags {
+		v, ok := comp.ParseTag(tag.Name)
+		if !ok {
+			diff.ignoredTags++
+			continue
+		}
+		diff.remote[tag.Name] = tag.SHA
+		diff.versions[tag.Name] = v
+	}
+	for _, v := range versions {
+		if _, ok := comp.ParseTag(v.TagName); !ok {
+			diff.ignoredVersions++
+			continue
+		}
+		diff.local[v.TagName] = v.GitSHA
+	}
+
+	// Find discrepancies
+	for tagName, remoteSHA := range diff.remote {
+		if localSHA, exists := diff.local[tagName]; !exists {
+			diff.remoteOnly = append(diff.remoteOnly, tagName)
+		} else if localSHA != remoteSHA {
+			diff.mismatched = append(diff.mismatched, tagName)
+		}
+	}
+	for tagName := range diff.local {
+		if _, exists := diff.remote[tagName]; !exists {
+			diff.localOnly = append(diff.localOnly, tagName)
+		}
+	}
+	sort.Strings(diff.remoteOnly)
+	sort.Strings(diff.localOnly)
+	sort.Strings(diff.mismatched)
+
+	return diff
+}
+
+// Reconciliation action statuses
+const (
+	actionPlanned = "planned"
+	actionDone    = "done"
+	actionFailed  = "failed"
+)
+
+// VetAction is one step of a reconciliation plan
+type VetAction struct {
+	Tag    string `yaml:"tag" json:"tag"`
+	Action string `yaml:"action" json:"action"`
+	OldSHA string `yaml:"old_sha,omitempty" json:"old_sha,omitempty"`
+	NewSHA string `yaml:"new_sha,omitempty" json:"new_sha,omitempty"`
+	Status string `yaml:"status" json:"status"` // planned, done or failed
+	Error  string `yaml:"error,omitempty" json:"error,omitempty"`
+}
+
+// strategyActions returns the reconciliation plan for remote-wins or
+// local-wins
+func strategyActions(strategy string, diff *tagDiff) []VetAction {
+	var actions []VetAction
+	if strategy == strategyRemoteWins {
+		for _, tag := range diff.remoteOnly {
+			actions = append(actions, VetAction{Tag: tag, Action: db.ActionImport, NewSHA: diff.remote[tag]})
+		}
+		for _, tag := range diff.localOnly {
+			actions = append(actions, VetAction{Tag: tag, Action: db.ActionDeleteLocal, OldSHA: diff.local[tag]})
+		}
+		for _, tag := range diff.mismatched {
+			actions = append(actions, VetAction{Tag: tag, Action: db.ActionUpdateLocal, OldSHA: diff.local[tag], NewSHA: diff.remote[tag]})
+		}
+		return actions
+	}
+
+	for _, tag := range diff.localOnly {
+		actions = append(actions, VetAction{Tag: tag, Action: db.ActionCreateRemote, NewSHA: diff.local[tag]})
+	}
+	for _, tag := range diff.mismatched {
+		actions = append(actions, VetAction{Tag: tag, Action: db.ActionRepointRemote, OldSHA: diff.remote[tag], NewSHA: diff.local[tag]})
+	}
+	return actions
+}
+
+// askReconciliation asks what to do about each discrepancy. Remote-only
+// tags are imported together after one confirmation. It returns the
+// actions chosen so far along with prompt.ErrNoInput if a question could
+// not be asked.
+func askReconciliation(diff *tagDiff) ([]VetAction, error) {
+	var actions []VetAction
+
+	if len(diff.remoteOnly) > 0 {
+		confirmed, err := vetConfirm(fmt.Sprintf("Import %d remote tags into qv.db?", len(diff.remoteOnly)))
+		if err != nil {
+			return actions, err
+		}
+		if confirmed {
+			for _, tag := range diff.remoteOnly {
+				actions = append(actions, VetAction{Tag: tag, Action: db.ActionImport, NewSHA: diff.remote[tag]})
+			}
+		}
+	}
+
+	for _, tag := range diff.localOnly {
+		choice, err := vetAsk(fmt.Sprintf("%s is only in qv.db: [d]elete it from qv.db, [c]reate the remote tag or [s]kip? ", tag))
+		if err != nil {
+			return actions, err
+		}
+		switch strings.ToLower(choice) {
+		case "d", "delete":
+			actions = append(actions, VetAction{Tag: tag, Action: db.ActionDeleteLocal, OldSHA: diff.local[tag]})
+		case "c", "create":
+			actions = append(actions, VetAction{Tag: tag, Action: db.ActionCreateRemote, NewSHA: diff.local[tag]})
+		}
+	}
+
+	for _, tag := range diff.mismatched {
+		local, remote := diff.local[tag], diff.remote[tag]
+		choice, err := vetAsk(fmt.Sprintf("%s is %s in qv.db but %s on the remote: [u]pdate qv.db, [m]ove the remote tag or [s]kip? ", tag, shortSHA(local), shortSHA(remote)))
+		if err != nil {
+			return actions, err
+		}
+		switch strings.ToLower(choice) {
+		case "u", "update":
+			actions = append(actions, VetAction{Tag: tag, Action: db.ActionUpdateLocal, OldSHA: local, NewSHA: remote})
+		case "m", "move":
+			actions = append(actions, VetAction{Tag: tag, Action: db.ActionRepointRemote, OldSHA: remote, NewSHA: local})
+		}
+	}
+
+	return actions, nil
+}
+
+// describeAction explains a reconciliation action in one line
+func describeAction(a VetAction) string {
+	switch a.Action {
+	case db.ActionImport:
+		return fmt.Sprintf("import %s into qv.db at %s", a.Tag, shortSHA(a.NewSHA))
+	case db.ActionDeleteLocal:
+		return fmt.Sprintf("delete %s (%s) from qv.db", a.Tag, shortSHA(a.OldSHA))
+	case db.ActionUpdateLocal:
+		return fmt.Sprintf("point %s in qv.db at %s (was %s)", a.Tag, shortSHA(a.NewSHA), shortSHA(a.OldSHA))
+	case db.ActionCreateRemote:
+		return fmt.Sprintf("create remote tag %s at %s", a.Tag, shortSHA(a.NewSHA))
+	case db.ActionRepointRemote:
+		return fmt.Sprintf("move remote tag %s to %s (was %s)", a.Tag, shortSHA(a.NewSHA), shortSHA(a.OldSHA))
+	default:
+		return a.Action + " " + a.Tag
+	}
+}
+
+// applyAction carries out a reconciliation action. remoteVersion is the
+// version parsed from the remote tag, used when importing it.
+func applyAction(ctx context.Context, client forge.Forge, database *db.DB, gitURL string, comp *component.Component, remoteVersion string, a VetAction) error {
+	switch a.Action {
+	case db.ActionImport:
+		parsed, err := version.Parse(remoteVersion)
+		if err != nil {
+			return fmt.Errorf("not a semantic version")
+		}
+		newVersion := &db.Version{
+			Version:   parsed.String(),
+			TagName:   a.Tag,
+			GitSHA:    a.NewSHA,
+			GitURL:    gitURL,
+			Component: comp.Name,
+		}
+		if channel := parsed.Channel(); channel != "" {
+			newVersion.Channel = &channel
+		}
+		return database.InsertVersion(newVersion)
+	case db.ActionDeleteLocal:
+		return database.DeleteVersion(gitURL, comp.Name, a.Tag)
+	case db.ActionUpdateLocal:
+		return database.SetVersionSHA(gitURL, comp.Name, a.Tag, a.NewSHA)
+	case db.ActionCreateRemote:
+		return client.CreateTag(ctx, a.Tag, a.NewSHA, fmt.Sprintf("Release %s", a.Tag))
+	case db.ActionRepointRemote:
+		if err := client.DeleteTag(ctx, a.Tag); err != nil {
+			return err
+		}
+		if err := client.CreateTag(ctx, a.Tag, a.NewSHA, fmt.Sprintf("Release %s", a.Tag)); err != nil {
+			return fmt.Errorf("tag %s was deleted, but recreating it failed: %w", a.Tag, err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown reconciliation action %q", a.Action)
+	}
+}
+
+// printAuditTrail lists the reconciliation actions recorded for a component
+func printAuditTrail(database *db.DB, gitURL, componentName string) error {
+	entries, err := database.GetReconciliations(gitURL, componentName, 0)
+	if err != nil {
+		return err
+	}
+
+	if machineOutput() {
+		trail := []AuditEntry{}
+		for _, r := range entries {
+			trail = append(trail, AuditEntry{
+				ID:          r.ID,
+				Tag:         r.TagName,
+				Action:      r.Action,
+				Strategy:    r.Strategy,
+				OldSHA:      valueOr(r.OldSHA, ""),
+				NewSHA:      valueOr(r.NewSHA, ""),
+				Actor:       valueOr(r.Actor, ""),
+				Hostname:    valueOr(r.Hostname, ""),
+				PerformedAt: r.PerformedAt,
+				Error:       valueOr(r.Error, ""),
+			})
+		}
+		return writeOutput(trail)
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No reconciliations recorded.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTAG\tACTION\tSTRATEGY\tOLD SHA\tNEW SHA\tACTOR\tHOST\tPERFORMED\tRESULT")
+	for _, r := range entries {
+		outcome := actionDone
+		if r.Error != nil {
+			outcome = actionFailed
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.ID, r.TagName, r.Action, r.Strategy,
+			shortSHA(valueOr(r.OldSHA, "")), shortSHA(valueOr(r.NewSHA, "")),
+			valueOr(r.Actor, "-"), valueOr(r.Hostname, "-"), r.PerformedAt, outcome)
+	}
+	w.Flush()
+
+	// Errors are too long for the table, list them afterwards
+	for _, r := range entries {
+		if r.Error != nil {
+			fmt.Printf("\n#%d %s %s: %s\n", r.ID, r.Action, r.TagName, *r.Error)
+		}
+	}
+
+	return nil
+}
+
+// AuditEntry is the machine-readable form of a recorded reconciliation
+type AuditEntry struct {
+	ID          int    `yaml:"id" json:"id"`
+	Tag         string `yaml:"tag" json:"tag"`
+	Action      string `yaml:"action" json:"action"`
+	Strategy    string `yaml:"strategy" json:"strategy"`
+	OldSHA      string `yaml:"old_sha,omitempty" json:"old_sha,omitempty"`
+	NewSHA      string `yaml:"new_sha,omitempty" json:"new_sha,omitempty"`
+	Actor       string `yaml:"actor,omitempty" json:"actor,omitempty"`
+	Hostname    string `yaml:"hostname,omitempty" json:"hostname,omitempty"`
+	PerformedAt string `yaml:"performed_at" json:"performed_at"`
+	Error       string `yaml:"error,omitempty" json:"error,omitempty"`
+}
+
+// shortSHA abbreviates a commit SHA for display, or returns "-" if empty
+func shortSHA(sha string) string {
+	if sha == "" {
+		return "-"
+	}
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// optional returns a pointer to s, or nil if s is empty
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// VetOutput is the machine-readable result of qv vet
+type VetOutput struct {
+	Repository      string      `yaml:"repository" json:"repository"`
+	Component       string      `yaml:"component,omitempty" json:"component,omitempty"`
+	Strategy        string      `yaml:"strategy" json:"strategy"`
+	DryRun          bool        `yaml:"dry_run" json:"dry_run"`
+	RemoteTags      int         `yaml:"remote_tags" json:"remote_tags"`
+	IgnoredTags     int         `yaml:"ignored_tags" json:"ignored_tags"`
+	LocalVersions   int         `yaml:"local_versions" json:"local_versions"`
+	IgnoredVersions int         `yaml:"ignored_versions" json:"ignored_versions"`
+	InSync          bool        `yaml:"in_sync" json:"in_sync"`
+	RemoteOnly      []string    `yaml:"remote_only" json:"remote_only"`
+	LocalOnly       []string    `yaml:"local_only" json:"local_only"`
+	Mismatched      []string    `yaml:"mismatched" json:"mismatched"`
+	MissingImages   []string    `yaml:"missing_images,omitempty" json:"missing_images,omitempty"`
+	Actions         []VetAction `yaml:"actions" json:"actions"`
+	Synced          []string    `yaml:"synced" json:"synced"`
+}
+
+// finishVet prints the vet result when a machine-readable format was
+// selected with --output
+func finishVet(result *VetOutput) error {
+	if !machineOutput() {
+		return nil
+	}
+	return writeOutput(result)
+}
+
 func init() {
 	rootCmd.AddCommand(vetCmd)
+	vetCmd.Flags().StringVar(&vetStrategy, "strategy", strategyInteractive, "how to reconcile discrepancies: remote-wins, local-wins or interactive")
+	vetCmd.Flags().BoolVar(&vetDryRun, "dry-run", false, "print the reconciliation plan without applying it")
+	vetCmd.Flags().BoolVar(&vetAudit, "audit", false, "list the reconciliation actions recorded in qv.db")
+	addComponentFlag(vetCmd)
+	addOutputFlag(vetCmd)
 }
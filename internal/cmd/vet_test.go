@@ -0,0 +1,324 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/excircle/quik-version/internal/component"
+	"github.com/excircle/quik-version/internal/db"
+	"github.com/excircle/quik-version/internal/forge"
+	"github.com/excircle/quik-version/internal/prompt"
+)
+
+const testGitURL = "https://example.com/acme/app"
+
+// fakeForge keeps tags in memory. Methods vet does not use panic through
+// the nil embedded interface.
+type fakeForge struct {
+	forge.Forge
+	tags map[string]string // tag name -> SHA
+}
+
+func (f *fakeForge) ListTags(ctx context.Context) ([]forge.Tag, error) {
+	var tags []forge.Tag
+	for name, sha := range f.tags {
+		tags = append(tags, forge.Tag{Name: name, SHA: sha})
+	}
+	return tags, nil
+}
+
+func (f *fakeForge) CreateTag(ctx context.Context, tagName, commitSHA, message string) error {
+	if _, exists := f.tags[tagName]; exists {
+		return fmt.Errorf("tag %s already exists", tagName)
+	}
+	f.tags[tagName] = commitSHA
+	return nil
+}
+
+func (f *fakeForge) DeleteTag(ctx context.Context, tagName string) error {
+	if _, exists := f.tags[tagName]; !exists {
+		return fmt.Errorf("tag %s not found", tagName)
+	}
+	delete(f.tags, tagName)
+	return nil
+}
+
+// newTestDB opens a migrated in-memory qv.db
+func newTestDB(t *testing.T) *db.DB {
+	t.Helper()
+	conn, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every connection to :memory: is a separate database
+	conn.SetMaxOpenConns(1)
+	t.Cleanup(func() { conn.Close() })
+
+	database := &db.DB{DB: conn}
+	if err := database.Migrate(); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	return database
+}
+
+// vetFixture returns a forge and qv.db that disagree in every way vet
+// reports:
+//   - v1.0.0 is in sync
+//   - v1.1.0 is only on the remote
+//   - v1.2.0 points to different commits
+//   - v0.9.0 is only in qv.db
+//   - nightly and release-0.8.0 do not match the tag template
+func vetFixture(t *testing.T) (*fakeForge, *db.DB) {
+	t.Helper()
+	client := &fakeForge{tags: map[string]string{
+		"v1.0.0":  "aaaaaaa",
+		"v1.1.0":  "bbbbbbb",
+		"v1.2.0":  "ccccccc",
+		"nightly": "fffffff",
+	}}
+
+	database := newTestDB(t)
+	rows := []db.Version{
+		{Version: "1.0.0", TagName: "v1.0.0", GitSHA: "aaaaaaa"},
+		{Version: "1.2.0", TagName: "v1.2.0", GitSHA: "xxxxxxx"},
+		{Version: "0.9.0", TagName: "v0.9.0", GitSHA: "ddddddd"},
+		{Version: "0.8.0", TagName: "release-0.8.0", GitSHA: "eeeeeee"},
+	}
+	for _, v := range rows {
+		v.GitURL = testGitURL
+		if err := database.InsertVersion(&v); err != nil {
+			t.Fatalf("InsertVersion: %v", err)
+		}
+	}
+	return client, database
+}
+
+// reconcileFixture diffs the fixture, plans the actions of strategy and
+// applies them
+func reconcileFixture(t *testing.T, strategy string, client *fakeForge, database *db.DB) {
+	t.Helper()
+	ctx := context.Background()
+	comp, err := component.Lookup("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tags, _ := client.ListTags(ctx)
+	versions, err := database.GetAllVersions(testGitURL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := newTagDiff(comp, tags, versions)
+
+	var actions []VetAction
+	if strategy == strategyInteractive {
+		actions, err = askReconciliation(diff)
+		if err != nil {
+			t.Fatalf("askReconciliation: %v", err)
+		}
+	} else {
+		actions = strategyActions(strategy, diff)
+	}
+
+	for _, a := range actions {
+		if err := applyAction(ctx, client, database, testGitURL, comp, diff.versions[a.Tag], a); err != nil {
+			t.Fatalf("%s: %v", describeAction(a), err)
+		}
+	}
+}
+
+// localTags returns the tag name -> SHA of every row in qv.db
+func localTags(t *testing.T, database *db.DB) map[string]string {
+	t.Helper()
+	versions, err := database.GetAllVersions(testGitURL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags := make(map[string]string)
+	for _, v := range versions {
+		tags[v.TagName] = v.GitSHA
+	}
+	return tags
+}
+
+func assertTags(t *testing.T, side string, got, want map[string]string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s tags = %v, want %v", side, got, want)
+		return
+	}
+	for name, sha := range want {
+		if got[name] != sha {
+			t.Errorf("%s tag %s = %q, want %q", side, name, got[name], sha)
+		}
+	}
+}
+
+func TestNewTagDiff(t *testing.T) {
+	client, database := vetFixture(t)
+	comp, err := component.Lookup("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags, _ := client.ListTags(context.Background())
+	versions, err := database.GetAllVersions(testGitURL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diff := newTagDiff(comp, tags, versions)
+
+	checks := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{"remote only", diff.remoteOnly, []string{"v1.1.0"}},
+		{"local only", diff.localOnly, []string{"v0.9.0"}},
+		{"mismatched", diff.mismatched, []string{"v1.2.0"}},
+	}
+	for _, c := range checks {
+		if strings.Join(c.got, ",") != strings.Join(c.want, ",") {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+	if diff.ignoredTags != 1 || diff.ignoredVersions != 1 {
+		t.Errorf("ignored tags, versions = %d, %d, want 1, 1", diff.ignoredTags, diff.ignoredVersions)
+	}
+	if diff.versions["v1.1.0"] != "1.1.0" {
+		t.Errorf("version of v1.1.0 = %q, want 1.1.0", diff.versions["v1.1.0"])
+	}
+}
+
+func TestVetStrategies(t *testing.T) {
+	tests := []struct {
+		strategy string
+		answers  []string // interactive answers, in order
+		remote   map[string]string
+		local    map[string]string
+	}{
+		{
+			strategy: strategyRemoteWins,
+			remote: map[string]string{
+				"v1.0.0": "aaaaaaa", "v1.1.0": "bbbbbbb", "v1.2.0": "ccccccc", "nightly": "fffffff",
+			},
+			local: map[string]string{
+				"v1.0.0": "aaaaaaa", "v1.1.0": "bbbbbbb", "v1.2.0": "ccccccc", "release-0.8.0": "eeeeeee",
+			},
+		},
+		{
+			strategy: strategyLocalWins,
+			remote: map[string]string{
+				"v0.9.0": "ddddddd", "v1.0.0": "aaaaaaa", "v1.1.0": "bbbbbbb", "v1.2.0": "xxxxxxx", "nightly": "fffffff",
+			},
+			local: map[string]string{
+				"v0.9.0": "ddddddd", "v1.0.0": "aaaaaaa", "v1.2.0": "xxxxxxx", "release-0.8.0": "eeeeeee",
+			},
+		},
+		{
+			// Import v1.1.0, create the v0.9.0 tag, point v1.2.0 in qv.db
+			// at the remote commit
+			strategy: strategyInteractive,
+			answers:  []string{"y", "c", "u"},
+			remote: map[string]string{
+				"v0.9.0": "ddddddd", "v1.0.0": "aaaaaaa", "v1.1.0": "bbbbbbb", "v1.2.0": "ccccccc", "nightly": "fffffff",
+			},
+			local: map[string]string{
+				"v0.9.0": "ddddddd", "v1.0.0": "aaaaaaa", "v1.1.0": "bbbbbbb", "v1.2.0": "ccccccc", "release-0.8.0": "eeeeeee",
+			},
+		},
+		{
+			// Decline the import, delete v0.9.0 from qv.db, skip v1.2.0
+			strategy: strategyInteractive,
+			answers:  []string{"n", "d", "s"},
+			remote: map[string]string{
+				"v1.0.0": "aaaaaaa", "v1.1.0": "bbbbbbb", "v1.2.0": "ccccccc", "nightly": "fffffff",
+			},
+			local: map[string]string{
+				"v1.0.0": "aaaaaaa", "v1.2.0": "xxxxxxx", "release-0.8.0": "eeeeeee",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy+strings.Join(tt.answers, ""), func(t *testing.T) {
+			answers := tt.answers
+			next := func(question string) (string, error) {
+				if len(answers) == 0 {
+					t.Fatalf("unexpected question %q", question)
+				}
+				answer := answers[0]
+				answers = answers[1:]
+				return answer, nil
+			}
+			vetAsk = next
+			vetConfirm = func(question string) (bool, error) {
+				answer, err := next(question)
+				return answer == "y", err
+			}
+			defer func() { vetAsk, vetConfirm = prompt.String, prompt.Confirm }()
+
+			client, database := vetFixture(t)
+			reconcileFixture(t, tt.strategy, client, database)
+
+			if len(answers) != 0 {
+				t.Errorf("unused answers %v", answers)
+			}
+			assertTags(t, "remote", client.tags, tt.remote)
+			assertTags(t, "local", localTags(t, database), tt.local)
+		})
+	}
+}
+
+func TestDeleteLocalRemovesImages(t *testing.T) {
+	client, database := vetFixture(t)
+	for _, version := range []string{"0.9.0", "1.0.0"} {
+		if _, err := database.Exec(`
+			INSERT INTO images (git_url, component, version, image, image_id, digest, tags)
+			VALUES (?, '', ?, 'app', 'id', 'sha256:x', 'app:'||?)
+		`, testGitURL, version, version); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// remote-wins deletes v0.9.0 from qv.db
+	reconcileFixture(t, strategyRemoteWins, client, database)
+
+	for version, want := range map[string]int{"0.9.0": 0, "1.0.0": 1} {
+		images, err := database.GetImages(testGitURL, "", version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(images) != want {
+			t.Errorf("images of %s = %d, want %d", version, len(images), want)
+		}
+	}
+}
+
+func TestAskReconciliationNoInput(t *testing.T) {
+	vetConfirm = func(string) (bool, error) { return false, prompt.ErrNoInput }
+	vetAsk = func(string) (string, error) { return "", prompt.ErrNoInput }
+	defer func() { vetAsk, vetConfirm = prompt.String, prompt.Confirm }()
+
+	client, database := vetFixture(t)
+	comp, err := component.Lookup("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags, _ := client.ListTags(context.Background())
+	versions, err := database.GetAllVersions(testGitURL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actions, err := askReconciliation(newTagDiff(comp, tags, versions))
+	if err != prompt.ErrNoInput {
+		t.Errorf("askReconciliation error = %v, want ErrNoInput", err)
+	}
+	if len(actions) != 0 {
+		t.Errorf("askReconciliation actions = %v, want none", actions)
+	}
+}
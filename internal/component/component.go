@@ -0,0 +1,178 @@
+package component
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/excircle/quik-version/internal/config"
+	"github.com/excircle/quik-version/internal/version"
+)
+
+// DefaultTagTemplate is used when version.tag_template is not configured
+const DefaultTagTemplate = "v{{.Version}}"
+
+// DefaultComponentTagTemplate is used for components without a tag_template
+// when version.tag_template is not configured
+const DefaultComponentTagTemplate = "{{.Component}}/v{{.Version}}"
+
+// versionSentinel stands in for the version when rendering a template so
+// the text around it can be recovered
+const versionSentinel = "\x00version\x00"
+
+// Component is an independently versioned part of a repository
+type Component struct {
+	Name        string
+	Path        string
+	TagTemplate string
+
+	// Text rendered before and after the version in tag names
+	tagPrefix string
+	tagSuffix string
+}
+
+// TagData is the data available to tag templates
+type TagData struct {
+	Version   string
+	Component string
+}
+
+// componentSentinel stands in for the component name when checking that a
+// template uses it
+const componentSentinel = "\x00component\x00"
+
+// Lookup returns the component with the given name from quik.conf. An
+// empty name returns the default component for the whole repository.
+func Lookup(name string) (*Component, error) {
+	if err := checkGlobalTagTemplate(); err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		return newComponent("", "", defaultTagTemplate(false))
+	}
+
+	for _, c := range config.GetComponents() {
+		if c.Name != name {
+			continue
+		}
+		tmpl := c.TagTemplate
+		if tmpl == "" {
+			tmpl = defaultTagTemplate(true)
+		}
+		return newComponent(c.Name, c.Path, tmpl)
+	}
+
+	return nil, fmt.Errorf("component %q not found in quik.conf", name)
+}
+
+// defaultTagTemplate returns version.tag_template, or the built-in default
+func defaultTagTemplate(isComponent bool) string {
+	if tmpl := config.GetTagTemplate(); tmpl != "" {
+		return tmpl
+	}
+	if isComponent {
+		return DefaultComponentTagTemplate
+	}
+	return DefaultTagTemplate
+}
+
+// checkGlobalTagTemplate rejects a version.tag_template without
+// {{.Component}} when several components are configured, since their tags
+// would collide
+func checkGlobalTagTemplate() error {
+	tmpl := config.GetTagTemplate()
+	if tmpl == "" || len(config.GetComponents()) < 2 {
+		return nil
+	}
+
+	c, err := newComponent(componentSentinel, "", tmpl)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(c.tagPrefix+c.tagSuffix, componentSentinel) {
+		return fmt.Errorf("version.tag_template %q must contain {{.Component}} when more than one component is configured", tmpl)
+	}
+	return nil
+}
+
+// newComponent validates the tag template and precomputes the text around
+// the version
+func newComponent(name, path, tagTemplate string) (*Component, error) {
+	// Accept the shorthand {{version}} placeholder
+	source := strings.ReplaceAll(tagTemplate, "{{version}}", "{{.Version}}")
+
+	tmpl, err := template.New("tag").Option("missingkey=error").Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tag template %q: %w", tagTemplate, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, TagData{Version: versionSentinel, Component: name}); err != nil {
+		return nil, fmt.Errorf("invalid tag template %q: %w", tagTemplate, err)
+	}
+
+	rendered := buf.String()
+	if strings.Count(rendered, versionSentinel) != 1 {
+		return nil, fmt.Errorf("tag template %q must contain {{.Version}} exactly once", tagTemplate)
+	}
+	prefix, suffix, _ := strings.Cut(rendered, versionSentinel)
+
+	return &Component{
+		Name:        name,
+		Path:        path,
+		TagTemplate: tagTemplate,
+		tagPrefix:   prefix,
+		tagSuffix:   suffix,
+	}, nil
+}
+
+// TagName returns the tag for a version of this component
+func (c *Component) TagName(v string) string {
+	return c.tagPrefix + v + c.tagSuffix
+}
+
+// ParseTag returns the version encoded in a tag if the tag matches this
+// component's template and holds a valid semantic version
+func (c *Component) ParseTag(tag string) (string, bool) {
+	if !strings.HasPrefix(tag, c.tagPrefix) || !strings.HasSuffix(tag, c.tagSuffix) || len(tag) <= len(c.tagPrefix)+len(c.tagSuffix) {
+		return "", false
+	}
+
+	// The template already supplies any 'v' prefix
+	v := tag[len(c.tagPrefix) : len(tag)-len(c.tagSuffix)]
+	if strings.HasPrefix(v, "v") {
+		return "", false
+	}
+
+	parsed, err := version.Parse(v)
+	if err != nil {
+		return "", false
+	}
+	return parsed.String(), true
+}
+
+// Touches reports whether any of the files lie under the component's path.
+// Components without a path own the whole repository.
+func (c *Component) Touches(files []string) bool {
+	if c.Path == "" {
+		return true
+	}
+
+	prefix := strings.TrimSuffix(c.Path, "/") + "/"
+	for _, f := range files {
+		if strings.HasPrefix(f, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Label returns a display name for the component
+func (c *Component) Label() string {
+	if c.Name == "" {
+		return "(default)"
+	}
+	return c.Name
+}
@@ -1,23 +1,48 @@
 package config
 
-import "github.com/spf13/viper"
+import (
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+)
 
 // Config represents the full configuration structure
 type Config struct {
-	Version VersionConfig `mapstructure:"version"`
-	Build   BuildConfig   `mapstructure:"build"`
-	Storage StorageConfig `mapstructure:"storage"`
+	Version    VersionConfig     `mapstructure:"version"`
+	Build      BuildConfig       `mapstructure:"build"`
+	Storage    StorageConfig     `mapstructure:"storage"`
+	Changelog  ChangelogConfig   `mapstructure:"changelog"`
+	Release    ReleaseConfig     `mapstructure:"release"`
+	PR         PRConfig          `mapstructure:"pr"`
+	Components []ComponentConfig `mapstructure:"components"`
+	Lines      []LineConfig      `mapstructure:"lines"`
+	BumpFiles  []BumpFileConfig  `mapstructure:"bump_files"`
 }
 
 // VersionConfig holds version-related settings
 type VersionConfig struct {
-	GitURL string `mapstructure:"git_url"`
-	Token  string `mapstructure:"token"`
+	GitURL      string `mapstructure:"git_url"`
+	Token       string `mapstructure:"token"`
+	Forge       string `mapstructure:"forge"`
+	APIURL      string `mapstructure:"api_url"`
+	UploadURL   string `mapstructure:"upload_url"`
+	Remote      string `mapstructure:"remote"`
+	RepoPath    string `mapstructure:"repo_path"`
+	TagTemplate string `mapstructure:"tag_template"`
 }
 
 // BuildConfig holds build-related settings
 type BuildConfig struct {
-	BuildManagement bool `mapstructure:"build_management"`
+	BuildManagement bool     `mapstructure:"build_management"`
+	Context         string   `mapstructure:"context"`
+	Containerfile   string   `mapstructure:"containerfile"`
+	Image           string   `mapstructure:"image"`
+	BuildArgs       []string `mapstructure:"build_args"`
+	Registry        string   `mapstructure:"registry"`
+	Username        string   `mapstructure:"username"`
+	Password        string   `mapstructure:"password"`
+	TLSVerify       *bool    `mapstructure:"tls_verify"`
 }
 
 // StorageConfig holds storage-related settings
@@ -25,6 +50,52 @@ type StorageConfig struct {
 	DBPath string `mapstructure:"db_path"`
 }
 
+// ChangelogConfig holds changelog generation settings
+type ChangelogConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Path    string `mapstructure:"path"`
+}
+
+// ReleaseConfig holds GitHub release settings
+type ReleaseConfig struct {
+	Enabled    bool `mapstructure:"enabled"`
+	Draft      bool `mapstructure:"draft"`
+	Prerelease bool `mapstructure:"prerelease"`
+}
+
+// PRConfig holds the settings of pull requests opened by qv pr
+type PRConfig struct {
+	Labels    []string `mapstructure:"labels"`
+	Reviewers []string `mapstructure:"reviewers"`
+	Assignees []string `mapstructure:"assignees"`
+	Draft     bool     `mapstructure:"draft"`
+	Milestone string   `mapstructure:"milestone"`
+}
+
+// ComponentConfig describes an independently versioned part of a monorepo
+type ComponentConfig struct {
+	Name        string           `mapstructure:"name"`
+	Path        string           `mapstructure:"path"`
+	TagTemplate string           `mapstructure:"tag_template"`
+	BumpFiles   []BumpFileConfig `mapstructure:"bump_files"`
+}
+
+// LineConfig describes a maintained MAJOR.MINOR release line and the
+// branch its patch releases are cut from
+type LineConfig struct {
+	Line   string `mapstructure:"line"`
+	Branch string `mapstructure:"branch"`
+}
+
+// BumpFileConfig describes a file whose version is rewritten on release.
+// Format is detected from the file name if empty; Pattern is a regular
+// expression with one capture group around the version.
+type BumpFileConfig struct {
+	Path    string `mapstructure:"path"`
+	Format  string `mapstructure:"format"`
+	Pattern string `mapstructure:"pattern"`
+}
+
 // Load reads the configuration from Viper into a Config struct
 func Load() (*Config, error) {
 	var config Config
@@ -44,12 +115,214 @@ func GetToken() string {
 	return viper.GetString("version.token")
 }
 
+// GetForge returns the configured forge kind (github, gitlab, gitea or git).
+// An empty value means the forge is detected from git_url.
+func GetForge() string {
+	return viper.GetString("version.forge")
+}
+
+// GetAPIURL returns the configured forge API base URL, used for GitHub
+// Enterprise Server and self-hosted GitLab or Gitea instances
+func GetAPIURL() string {
+	return viper.GetString("version.api_url")
+}
+
+// GetUploadURL returns the configured GitHub Enterprise upload URL. When
+// unset, the GitHub client derives it from the API URL.
+func GetUploadURL() string {
+	return viper.GetString("version.upload_url")
+}
+
+// GetRemote returns the git remote (name or URL) used by the local git
+// backend, defaulting to git_url
+func GetRemote() string {
+	if remote := viper.GetString("version.remote"); remote != "" {
+		return remote
+	}
+	return GetGitURL()
+}
+
+// GetRepoPath returns the local clone used by the local git backend
+// (default ".")
+func GetRepoPath() string {
+	if path := viper.GetString("version.repo_path"); path != "" {
+		return path
+	}
+	return "."
+}
+
+// GetTagTemplate returns the configured tag template (a Go template with
+// .Version and .Component), or an empty string for the default
+func GetTagTemplate() string {
+	return viper.GetString("version.tag_template")
+}
+
 // GetBuildManagement returns whether build management is enabled
 func GetBuildManagement() bool {
 	return viper.GetBool("build.build_management")
 }
 
+// GetBuildContext returns the build context directory (default ".")
+func GetBuildContext() string {
+	if context := viper.GetString("build.context"); context != "" {
+		return context
+	}
+	return "."
+}
+
+// GetBuildContainerfile returns the Containerfile path relative to the
+// build context (default "Containerfile")
+func GetBuildContainerfile() string {
+	if containerfile := viper.GetString("build.containerfile"); containerfile != "" {
+		return containerfile
+	}
+	return "Containerfile"
+}
+
+// GetBuildImage returns the image name to tag builds with
+func GetBuildImage() string {
+	return viper.GetString("build.image")
+}
+
+// GetBuildArgs returns the configured KEY=VALUE build arguments
+func GetBuildArgs() []string {
+	return viper.GetStringSlice("build.build_args")
+}
+
+// GetBuildRegistry returns the registry host images are pushed to. Images
+// are only pushed when a registry is configured.
+func GetBuildRegistry() string {
+	return viper.GetString("build.registry")
+}
+
+// GetRegistryUsername returns the registry username, preferring the
+// QV_REGISTRY_USERNAME environment variable
+func GetRegistryUsername() string {
+	if username := os.Getenv("QV_REGISTRY_USERNAME"); username != "" {
+		return username
+	}
+	return viper.GetString("build.username")
+}
+
+// GetRegistryPassword returns the registry password, preferring the
+// QV_REGISTRY_PASSWORD environment variable
+func GetRegistryPassword() string {
+	if password := os.Getenv("QV_REGISTRY_PASSWORD"); password != "" {
+		return password
+	}
+	return viper.GetString("build.password")
+}
+
+// GetRegistryTLSVerify returns whether registry TLS certificates are
+// verified (default true)
+func GetRegistryTLSVerify() bool {
+	if !viper.IsSet("build.tls_verify") {
+		return true
+	}
+	return viper.GetBool("build.tls_verify")
+}
+
 // GetDBPath returns the configured database path
 func GetDBPath() string {
 	return viper.GetString("storage.db_path")
 }
+
+// GetChangelogEnabled returns whether deploy should update the changelog
+func GetChangelogEnabled() bool {
+	return viper.GetBool("changelog.enabled")
+}
+
+// GetChangelogPath returns the configured changelog path
+func GetChangelogPath() string {
+	return viper.GetString("changelog.path")
+}
+
+// GetReleaseEnabled returns whether deploy should publish a GitHub release
+func GetReleaseEnabled() bool {
+	return viper.GetBool("release.enabled")
+}
+
+// GetReleaseDraft returns whether releases are created as drafts
+func GetReleaseDraft() bool {
+	return viper.GetBool("release.draft")
+}
+
+// GetReleasePrerelease returns whether releases are always marked as pre-releases
+func GetReleasePrerelease() bool {
+	return viper.GetBool("release.prerelease")
+}
+
+// GetPRLabels returns the labels added to pull requests
+func GetPRLabels() []string {
+	return viper.GetStringSlice("pr.labels")
+}
+
+// GetPRReviewers returns the users (or org/team slugs on GitHub) asked to
+// review pull requests
+func GetPRReviewers() []string {
+	return viper.GetStringSlice("pr.reviewers")
+}
+
+// GetPRAssignees returns the users assigned to pull requests
+func GetPRAssignees() []string {
+	return viper.GetStringSlice("pr.assignees")
+}
+
+// GetPRDraft returns whether pull requests are opened as drafts
+func GetPRDraft() bool {
+	return viper.GetBool("pr.draft")
+}
+
+// GetPRMilestone returns the title of the milestone set on pull requests
+func GetPRMilestone() string {
+	return viper.GetString("pr.milestone")
+}
+
+// GetComponents returns the configured monorepo components
+func GetComponents() []ComponentConfig {
+	var components []ComponentConfig
+	if err := viper.UnmarshalKey("components", &components); err != nil {
+		return nil
+	}
+	return components
+}
+
+// GetLines returns the configured maintenance release lines
+func GetLines() []LineConfig {
+	var lines []LineConfig
+	if err := viper.UnmarshalKey("lines", &lines); err != nil {
+		return nil
+	}
+	return lines
+}
+
+// GetLineBranch returns the maintenance branch for a release line, which
+// defaults to release/<line>
+func GetLineBranch(line string) string {
+	for _, l := range GetLines() {
+		if strings.TrimPrefix(l.Line, "v") == line && l.Branch != "" {
+			return l.Branch
+		}
+	}
+	return "release/" + line
+}
+
+// GetBumpFiles returns the files whose version is rewritten on release:
+// the component's bump_files, or the top-level bump_files for the default
+// component
+func GetBumpFiles(component string) []BumpFileConfig {
+	if component != "" {
+		for _, c := range GetComponents() {
+			if c.Name == component {
+				return c.BumpFiles
+			}
+		}
+		return nil
+	}
+
+	var files []BumpFileConfig
+	if err := viper.UnmarshalKey("bump_files", &files); err != nil {
+		return nil
+	}
+	return files
+}
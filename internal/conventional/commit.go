@@ -0,0 +1,79 @@
+package conventional
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// headerPattern matches a Conventional Commits header: type(scope)!: subject
+var headerPattern = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^()]*)\))?(!)?: (.+)$`)
+
+// Commit represents a parsed Conventional Commits message
+type Commit struct {
+	Type     string
+	Scope    string
+	Subject  string
+	Body     string
+	Breaking bool
+}
+
+// Parse parses a commit message following the Conventional Commits
+// specification. Messages without a valid header return an error.
+func Parse(message string) (*Commit, error) {
+	message = strings.TrimSpace(message)
+	header, body, _ := strings.Cut(message, "\n")
+
+	match := headerPattern.FindStringSubmatch(strings.TrimSpace(header))
+	if match == nil {
+		return nil, fmt.Errorf("not a conventional commit: %s", header)
+	}
+
+	commit := &Commit{
+		Type:     strings.ToLower(match[1]),
+		Scope:    match[2],
+		Subject:  strings.TrimSpace(match[4]),
+		Body:     strings.TrimSpace(body),
+		Breaking: match[3] == "!",
+	}
+
+	// A BREAKING CHANGE footer marks the commit as breaking regardless of type
+	for _, line := range strings.Split(commit.Body, "\n") {
+		if strings.HasPrefix(line, "BREAKING CHANGE:") || strings.HasPrefix(line, "BREAKING-CHANGE:") {
+			commit.Breaking = true
+			break
+		}
+	}
+
+	return commit, nil
+}
+
+// Increment returns the increment type implied by the commit:
+// "major" for breaking changes, "minor" for features, "patch" for fixes
+// and performance improvements, or an empty string if it is not releasable
+func (c *Commit) Increment() string {
+	switch {
+	case c.Breaking:
+		return "major"
+	case c.Type == "feat":
+		return "minor"
+	case c.Type == "fix" || c.Type == "perf":
+		return "patch"
+	default:
+		return ""
+	}
+}
+
+// Increment returns the highest increment type implied by a set of commits,
+// or an empty string if none of them are releasable
+func Increment(commits []*Commit) string {
+	rank := map[string]int{"": 0, "patch": 1, "minor": 2, "major": 3}
+
+	highest := ""
+	for _, c := range commits {
+		if inc := c.Increment(); rank[inc] > rank[highest] {
+			highest = inc
+		}
+	}
+	return highest
+}
@@ -0,0 +1,143 @@
+package conventional
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		message string
+		want    Commit
+	}{
+		{
+			message: "feat: add login",
+			want:    Commit{Type: "feat", Subject: "add login"},
+		},
+		{
+			message: "fix(parser): handle empty input",
+			want:    Commit{Type: "fix", Scope: "parser", Subject: "handle empty input"},
+		},
+		{
+			message: "Feat(ui): Upper-case type",
+			want:    Commit{Type: "feat", Scope: "ui", Subject: "Upper-case type"},
+		},
+		{
+			message: "  docs: trim surrounding whitespace  \n",
+			want:    Commit{Type: "docs", Subject: "trim surrounding whitespace"},
+		},
+		{
+			message: "refactor!: drop the v1 API",
+			want:    Commit{Type: "refactor", Subject: "drop the v1 API", Breaking: true},
+		},
+		{
+			message: "feat(api)!: rename endpoints",
+			want:    Commit{Type: "feat", Scope: "api", Subject: "rename endpoints", Breaking: true},
+		},
+		{
+			message: "fix: use new config\n\nReads config.yaml.\n\nBREAKING CHANGE: config.json is no longer read",
+			want: Commit{
+				Type:     "fix",
+				Subject:  "use new config",
+				Body:     "Reads config.yaml.\n\nBREAKING CHANGE: config.json is no longer read",
+				Breaking: true,
+			},
+		},
+		{
+			message: "chore: bump deps\n\nBREAKING-CHANGE: requires Go 1.24",
+			want: Commit{
+				Type:     "chore",
+				Subject:  "bump deps",
+				Body:     "BREAKING-CHANGE: requires Go 1.24",
+				Breaking: true,
+			},
+		},
+		{
+			message: "fix: mention it\n\nThis is not a BREAKING CHANGE: footer",
+			want: Commit{
+				Type:    "fix",
+				Subject: "mention it",
+				Body:    "This is not a BREAKING CHANGE: footer",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.message)
+		if err != nil {
+			t.Errorf("Parse(%q) returned error: %v", tt.message, err)
+			continue
+		}
+		if *got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.message, *got, tt.want)
+		}
+	}
+}
+
+func TestParseNonConventional(t *testing.T) {
+	tests := []string{
+		"",
+		"Merge branch 'main' into feature",
+		"add login",
+		"feat add login",
+		"feat:add login",
+		"feat: ",
+		"feat(): ",
+		"feat(a(b)): nested scope",
+		"1.2.3: release",
+		"feat!!: twice",
+	}
+
+	for _, message := range tests {
+		if c, err := Parse(message); err == nil {
+			t.Errorf("Parse(%q) = %+v, want error", message, *c)
+		}
+	}
+}
+
+func TestCommitIncrement(t *testing.T) {
+	tests := []struct {
+		commit Commit
+		want   string
+	}{
+		{Commit{Type: "feat"}, "minor"},
+		{Commit{Type: "fix"}, "patch"},
+		{Commit{Type: "perf"}, "patch"},
+		{Commit{Type: "docs"}, ""},
+		{Commit{Type: "chore"}, ""},
+		{Commit{Type: "docs", Breaking: true}, "major"},
+		{Commit{Type: "fix", Breaking: true}, "major"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.commit.Increment(); got != tt.want {
+			t.Errorf("%+v.Increment() = %q, want %q", tt.commit, got, tt.want)
+		}
+	}
+}
+
+func TestIncrement(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []string
+		want     string
+	}{
+		{"none", nil, ""},
+		{"not releasable", []string{"docs: readme", "chore: tidy"}, ""},
+		{"fix", []string{"docs: readme", "fix: crash"}, "patch"},
+		{"feat over fix", []string{"fix: crash", "feat: login", "fix: typo"}, "minor"},
+		{"breaking over feat", []string{"feat: login", "fix!: drop flag", "fix: typo"}, "major"},
+		{"breaking footer", []string{"feat: login", "chore: deps\n\nBREAKING CHANGE: Go 1.24"}, "major"},
+	}
+
+	for _, tt := range tests {
+		var commits []*Commit
+		for _, m := range tt.messages {
+			c, err := Parse(m)
+			if err != nil {
+				t.Fatalf("%s: Parse(%q) returned error: %v", tt.name, m, err)
+			}
+			commits = append(commits, c)
+		}
+		if got := Increment(commits); got != tt.want {
+			t.Errorf("%s: Increment() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -1,10 +1,13 @@
 package db
 
 import (
+	"crypto/sha256"
 	"database/sql"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	_ "github.com/mattn/go-sqlite3"
 
@@ -12,25 +15,6 @@ import (
 	"github.com/excircle/quik-version/internal/version"
 )
 
-const schema = `
-CREATE TABLE IF NOT EXISTS versions (
-    id INTEGER PRIMARY KEY AUTOINCREMENT,
-    version TEXT NOT NULL,
-    tag_name TEXT NOT NULL,
-    git_sha TEXT NOT NULL,
-    git_url TEXT NOT NULL,
-    increment_type TEXT,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    UNIQUE(git_url, version)
-);
-
-CREATE TABLE IF NOT EXISTS config_state (
-    id INTEGER PRIMARY KEY CHECK (id = 1),
-    last_synced_at TIMESTAMP,
-    git_url TEXT NOT NULL
-);
-`
-
 // DB wraps the SQLite database connection
 type DB struct {
 	*sql.DB
@@ -53,7 +37,7 @@ func Exists() bool {
 	return err == nil
 }
 
-// Open opens or creates the database
+// Open opens or creates the database and applies pending migrations
 func Open() (*DB, error) {
 	path := GetDBPath()
 
@@ -70,13 +54,19 @@ func Open() (*DB, error) {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	return &DB{DB: db, path: path}, nil
+	database := &DB{DB: db, path: path}
+	if err := database.Migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return database, nil
 }
 
-// Initialize creates the database schema
+// Initialize creates the database schema. Open already applies all
+// migrations, so this only re-checks that the schema is current.
 func (db *DB) Initialize() error {
-	_, err := db.Exec(schema)
-	if err != nil {
+	if err := db.Migrate(); err != nil {
 		return fmt.Errorf("failed to create schema: %w", err)
 	}
 	return nil
@@ -95,9 +85,10 @@ func (db *DB) SetConfigState(gitURL string) error {
 	return nil
 }
 
-// GetLatestVersion returns the latest version record for a git URL
-func (db *DB) GetLatestVersion(gitURL string) (*Version, error) {
-	versions, err := db.GetAllVersions(gitURL)
+// GetLatestVersion returns the latest version record for a component of a
+// git URL. The empty component is the repository's default version stream.
+func (db *DB) GetLatestVersion(gitURL, component string) (*Version, error) {
+	versions, err := db.GetAllVersions(gitURL, component)
 	if err != nil {
 		return nil, err
 	}
@@ -106,51 +97,127 @@ func (db *DB) GetLatestVersion(gitURL string) (*Version, error) {
 		return nil, nil
 	}
 
-	// Find the highest version using semver comparison
+	// Find the highest version using semver precedence
 	var latest *Version
-	var latestMajor, latestMinor, latestPatch int
+	var latestParsed *version.Version
 
 	for i := range versions {
 		v := &versions[i]
-		major, minor, patch, err := version.Parse(v.Version)
+		parsed, err := version.Parse(v.Version)
 		if err != nil {
 			continue // Skip invalid versions
 		}
 
-		if latest == nil ||
-			major > latestMajor ||
-			(major == latestMajor && minor > latestMinor) ||
-			(major == latestMajor && minor == latestMinor && patch > latestPatch) {
+		if latest == nil || latestParsed.LessThan(parsed) {
 			latest = v
-			latestMajor = major
-			latestMinor = minor
-			latestPatch = patch
+			latestParsed = parsed
 		}
 	}
 
 	return latest, nil
 }
 
+// GetLatestVersionInLine returns the latest version record of a release
+// line (e.g. 1.8) for a component of a git URL, or nil if the line has no
+// versions
+func (db *DB) GetLatestVersionInLine(gitURL, component string, line version.Line) (*Version, error) {
+	history, err := db.GetVersionHistory(gitURL, component)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(history) - 1; i >= 0; i-- {
+		parsed, err := version.Parse(history[i].Version)
+		if err == nil && line.Contains(parsed) {
+			return &history[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// GetVersionHistory returns all valid semantic versions for a component of
+// a git URL, ordered from lowest to highest precedence
+func (db *DB) GetVersionHistory(gitURL, component string) ([]Version, error) {
+	versions, err := db.GetAllVersions(gitURL, component)
+	if err != nil {
+		return nil, err
+	}
+
+	type parsedVersion struct {
+		record Version
+		parsed *version.Version
+	}
+
+	var valid []parsedVersion
+	for _, v := range versions {
+		parsed, err := version.Parse(v.Version)
+		if err != nil {
+			continue // Skip invalid versions
+		}
+		valid = append(valid, parsedVersion{record: v, parsed: parsed})
+	}
+
+	sort.SliceStable(valid, func(i, j int) bool {
+		return valid[i].parsed.LessThan(valid[j].parsed)
+	})
+
+	history := make([]Version, len(valid))
+	for i, v := range valid {
+		history[i] = v.record
+	}
+	return history, nil
+}
+
 // InsertVersion adds a new version record
 func (db *DB) InsertVersion(v *Version) error {
 	_, err := db.Exec(`
-		INSERT INTO versions (version, tag_name, git_sha, git_url, increment_type)
-		VALUES (?, ?, ?, ?, ?)
-	`, v.Version, v.TagName, v.GitSHA, v.GitURL, v.IncrementType)
+		INSERT INTO versions (version, tag_name, git_sha, git_url, component, increment_type, channel)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, v.Version, v.TagName, v.GitSHA, v.GitURL, v.Component, v.IncrementType, v.Channel)
 	if err != nil {
 		return fmt.Errorf("failed to insert version: %w", err)
 	}
 	return nil
 }
 
-// GetAllVersions returns all versions for a git URL
-func (db *DB) GetAllVersions(gitURL string) ([]Version, error) {
+// StateChecksum returns a SHA-256 checksum of the versions recorded for a
+// component of a git URL. It changes whenever a version is added, removed
+// or re-pointed, so a plan can detect that qv.db moved on since it was made.
+func (db *DB) StateChecksum(gitURL, component string) (string, error) {
+	versions, err := db.GetAllVersions(gitURL, component)
+	if err != nil {
+		return "", err
+	}
+
+	lines := make([]string, len(versions))
+	for i, v := range versions {
+		lines[i] = v.TagName + "\t" + v.Version + "\t" + v.GitSHA
+	}
+	sort.Strings(lines)
+
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(lines, "\n")))), nil
+}
+
+// SetRelease records the release published for a version
+func (db *DB) SetRelease(gitURL, component, version string, releaseID int64, releaseURL string) error {
+	_, err := db.Exec(`
+		UPDATE versions SET release_id = ?, release_url = ?
+		WHERE git_url = ? AND component = ? AND version = ?
+	`, releaseID, releaseURL, gitURL, component, version)
+	if err != nil {
+		return fmt.Errorf("failed to record release: %w", err)
+	}
+	return nil
+}
+
+// GetAllVersions returns all versions for a component of a git URL
+func (db *DB) GetAllVersions(gitURL, component string) ([]Version, error) {
 	rows, err := db.Query(`
-		SELECT id, version, tag_name, git_sha, git_url, increment_type, created_at
+		SELECT id, version, tag_name, git_sha, git_url, component, increment_type, channel, release_id, release_url, created_at
 		FROM versions
-		WHERE git_url = ?
+		WHERE git_url = ? AND component = ?
 		ORDER BY created_at DESC
-	`, gitURL)
+	`, gitURL, component)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query versions: %w", err)
 	}
@@ -159,7 +226,7 @@ func (db *DB) GetAllVersions(gitURL string) ([]Version, error) {
 	var versions []Version
 	for rows.Next() {
 		var v Version
-		if err := rows.Scan(&v.ID, &v.Version, &v.TagName, &v.GitSHA, &v.GitURL, &v.IncrementType, &v.CreatedAt); err != nil {
+		if err := rows.Scan(&v.ID, &v.Version, &v.TagName, &v.GitSHA, &v.GitURL, &v.Component, &v.IncrementType, &v.Channel, &v.ReleaseID, &v.ReleaseURL, &v.CreatedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan version: %w", err)
 		}
 		versions = append(versions, v)
@@ -174,6 +241,453 @@ type Version struct {
 	TagName       string
 	GitSHA        string
 	GitURL        string
+	Component     string
 	IncrementType *string
+	Channel       *string
+	ReleaseID     *int64
+	ReleaseURL    *string
 	CreatedAt     string
 }
+
+// GetImages returns the images built for a version, newest first
+func (db *DB) GetImages(gitURL, component, version string) ([]Image, error) {
+	rows, err := db.Query(`
+		SELECT id, git_url, component, version, image, image_id, digest, tags, pushed_digest, created_at
+		FROM images
+		WHERE git_url = ? AND component = ? AND version = ?
+		ORDER BY created_at DESC
+	`, gitURL, component, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query images: %w", err)
+	}
+	defer rows.Close()
+
+	var images []Image
+	for rows.Next() {
+		var img Image
+		if err := rows.Scan(&img.ID, &img.GitURL, &img.Component, &img.Version, &img.Image, &img.ImageID, &img.Digest, &img.Tags, &img.PushedDigest, &img.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan image: %w", err)
+		}
+		images = append(images, img)
+	}
+	return images, nil
+}
+
+// GetImageVersions returns the set of versions of a component that have a
+// pushed image
+func (db *DB) GetImageVersions(gitURL, component string) (map[string]string, error) {
+	rows, err := db.Query(`
+		SELECT version, pushed_digest
+		FROM images
+		WHERE git_url = ? AND component = ? AND pushed_digest IS NOT NULL
+		ORDER BY created_at
+	`, gitURL, component)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query images: %w", err)
+	}
+	defer rows.Close()
+
+	images := make(map[string]string) // version -> pushed digest
+	for rows.Next() {
+		var version, digest string
+		if err := rows.Scan(&version, &digest); err != nil {
+			return nil, fmt.Errorf("failed to scan image: %w", err)
+		}
+		images[version] = digest
+	}
+	return images, nil
+}
+
+// Image represents a container image built for a version
+type Image struct {
+	ID           int
+	GitURL       string
+	Component    string
+	Version      string
+	Image        string
+	ImageID      string
+	Digest       string
+	Tags         string  // comma-separated image references
+	PushedDigest *string // digest reported by the registry, nil if not pushed
+	CreatedAt    string
+}
+
+// Deployment outcomes
+const (
+	OutcomeRunning    = "running"
+	OutcomeSuccess    = "success"
+	OutcomeFailed     = "failed"
+	OutcomeRolledBack = "rolled_back"
+)
+
+// StartDeployment records the start of a deploy and sets d.ID. The
+// deployment stays in the running state until FinishDeployment is called.
+func (db *DB) StartDeployment(d *Deployment) error {
+	result, err := db.Exec(`
+		INSERT INTO deployments (git_url, component, version, tag_name, actor, hostname, plan_hash, outcome)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, d.GitURL, d.Component, d.Version, d.TagName, d.Actor, d.Hostname, d.PlanHash, OutcomeRunning)
+	if err != nil {
+		return fmt.Errorf("failed to record deployment: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to record deployment: %w", err)
+	}
+	d.ID = int(id)
+	d.Outcome = OutcomeRunning
+	return nil
+}
+
+// FinishDeployment records the outcome of a deploy. A nil deployErr marks
+// the deployment successful, otherwise it failed with deployErr's text.
+func (db *DB) FinishDeployment(id int, deployErr error) error {
+	outcome := OutcomeSuccess
+	var errText *string
+	if deployErr != nil {
+		outcome = OutcomeFailed
+		text := deployErr.Error()
+		errText = &text
+	}
+
+	_, err := db.Exec(`
+		UPDATE deployments SET outcome = ?, error = ?, finished_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, outcome, errText, id)
+	if err != nil {
+		return fmt.Errorf("failed to record deployment outcome: %w", err)
+	}
+	return nil
+}
+
+// GetUnfinishedDeployment returns the latest deployment of a component if
+// it failed or never finished, or nil if the latest deployment succeeded
+// or was rolled back
+func (db *DB) GetUnfinishedDeployment(gitURL, component string) (*Deployment, error) {
+	deployments, err := db.GetDeployments(DeploymentFilter{GitURL: gitURL, Component: component, Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(deployments) == 0 {
+		return nil, nil
+	}
+
+	latest := deployments[0]
+	if latest.Outcome != OutcomeRunning && latest.Outcome != OutcomeFailed {
+		return nil, nil
+	}
+	return &latest, nil
+}
+
+// ResumeDeployment puts a failed deployment back in the running state
+func (db *DB) ResumeDeployment(id int) error {
+	_, err := db.Exec(`
+		UPDATE deployments SET outcome = ?, error = NULL, finished_at = NULL
+		WHERE id = ?
+	`, OutcomeRunning, id)
+	if err != nil {
+		return fmt.Errorf("failed to resume deployment: %w", err)
+	}
+	return nil
+}
+
+// RollBackDeployment marks a deployment and all of its completed steps as
+// rolled back
+func (db *DB) RollBackDeployment(id int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to roll back deployment: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		UPDATE deployments SET outcome = ?, finished_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, OutcomeRolledBack, id); err != nil {
+		return fmt.Errorf("failed to roll back deployment: %w", err)
+	}
+	if _, err := tx.Exec(`
+		UPDATE deployment_steps SET status = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE deployment_id = ? AND status = ?
+	`, StepRolledBack, id, StepDone); err != nil {
+		return fmt.Errorf("failed to roll back deployment steps: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Deployment step statuses
+const (
+	StepDone       = "done"
+	StepFailed     = "failed"
+	StepSkipped    = "skipped"
+	StepRolledBack = "rolled_back"
+)
+
+// SetDeploymentStep records the status of a deploy step, along with the
+// data it produced (a SHA, digest or JSON document) or the error it failed
+// with
+func (db *DB) SetDeploymentStep(deploymentID int, step, status, data string, stepErr error) error {
+	var errText *string
+	if stepErr != nil {
+		text := stepErr.Error()
+		errText = &text
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO deployment_steps (deployment_id, step, status, data, error)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(deployment_id, step) DO UPDATE SET
+			status = excluded.status, data = excluded.data, error = excluded.error, updated_at = CURRENT_TIMESTAMP
+	`, deploymentID, step, status, data, errText)
+	if err != nil {
+		return fmt.Errorf("failed to record deploy step %s: %w", step, err)
+	}
+	return nil
+}
+
+// GetDeploymentSteps returns the recorded steps of a deployment by name
+func (db *DB) GetDeploymentSteps(deploymentID int) (map[string]DeploymentStep, error) {
+	rows, err := db.Query(`
+		SELECT step, status, data, error, updated_at
+		FROM deployment_steps
+		WHERE deployment_id = ?
+	`, deploymentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deploy steps: %w", err)
+	}
+	defer rows.Close()
+
+	steps := make(map[string]DeploymentStep)
+	for rows.Next() {
+		var s DeploymentStep
+		var data sql.NullString
+		if err := rows.Scan(&s.Step, &s.Status, &data, &s.Error, &s.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan deploy step: %w", err)
+		}
+		s.Data = data.String
+		steps[s.Step] = s
+	}
+	return steps, nil
+}
+
+// DeploymentStep represents one recorded step of a deployment
+type DeploymentStep struct {
+	Step      string
+	Status    string // done, failed, skipped or rolled_back
+	Data      string
+	Error     *string
+	UpdatedAt string
+}
+
+// RecordVersion inserts a version and, if img is non-nil, its image in a
+// single transaction
+func (db *DB) RecordVersion(v *Version, img *Image) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to record version: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO versions (version, tag_name, git_sha, git_url, component, increment_type, channel)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, v.Version, v.TagName, v.GitSHA, v.GitURL, v.Component, v.IncrementType, v.Channel); err != nil {
+		return fmt.Errorf("failed to insert version: %w", err)
+	}
+
+	if img != nil {
+		if _, err := tx.Exec(`
+			INSERT INTO images (git_url, component, version, image, image_id, digest, tags, pushed_digest)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`, img.GitURL, img.Component, img.Version, img.Image, img.ImageID, img.Digest, img.Tags, img.PushedDigest); err != nil {
+			return fmt.Errorf("failed to insert image: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeploymentFilter selects deployments for GetDeployments. Empty fields
+// match everything; Component is only applied when AllComponents is false.
+type DeploymentFilter struct {
+	GitURL        string
+	Component     string
+	AllComponents bool
+	Version       string
+	Actor         string
+	Outcome       string
+	Limit         int
+}
+
+// GetDeployments returns the deployments matching filter, newest first
+func (db *DB) GetDeployments(filter DeploymentFilter) ([]Deployment, error) {
+	query := `
+		SELECT id, git_url, component, version, tag_name, actor, hostname, plan_hash, started_at, finished_at, outcome, error
+		FROM deployments
+		WHERE git_url = ?`
+	args := []any{filter.GitURL}
+
+	if !filter.AllComponents {
+		query += " AND component = ?"
+		args = append(args, filter.Component)
+	}
+	if filter.Version != "" {
+		query += " AND version = ?"
+		args = append(args, filter.Version)
+	}
+	if filter.Actor != "" {
+		query += " AND actor = ?"
+		args = append(args, filter.Actor)
+	}
+	if filter.Outcome != "" {
+		query += " AND outcome = ?"
+		args = append(args, filter.Outcome)
+	}
+	query += " ORDER BY id DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deployments: %w", err)
+	}
+	defer rows.Close()
+
+	var deployments []Deployment
+	for rows.Next() {
+		var d Deployment
+		if err := rows.Scan(&d.ID, &d.GitURL, &d.Component, &d.Version, &d.TagName, &d.Actor, &d.Hostname, &d.PlanHash, &d.StartedAt, &d.FinishedAt, &d.Outcome, &d.Error); err != nil {
+			return nil, fmt.Errorf("failed to scan deployment: %w", err)
+		}
+		deployments = append(deployments, d)
+	}
+	return deployments, nil
+}
+
+// Deployment represents one run of qv deploy
+type Deployment struct {
+	ID         int
+	GitURL     string
+	Component  string
+	Version    string
+	TagName    string
+	Actor      *string
+	Hostname   *string
+	PlanHash   *string // sha256 of the plan.yaml that was deployed
+	StartedAt  string
+	FinishedAt *string
+	Outcome    string // running, success, failed or rolled_back
+	Error      *string
+}
+
+// DeleteVersion removes the version record of a tag and the images built
+// for it in a single transaction
+func (db *DB) DeleteVersion(gitURL, component, tagName string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to delete version: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		DELETE FROM images
+		WHERE git_url = ? AND component = ? AND version IN (
+			SELECT version FROM versions WHERE git_url = ? AND component = ? AND tag_name = ?
+		)
+	`, gitURL, component, gitURL, component, tagName); err != nil {
+		return fmt.Errorf("failed to delete images: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		DELETE FROM versions WHERE git_url = ? AND component = ? AND tag_name = ?
+	`, gitURL, component, tagName); err != nil {
+		return fmt.Errorf("failed to delete version: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// SetVersionSHA re-points the version record of a tag to another commit
+func (db *DB) SetVersionSHA(gitURL, component, tagName, sha string) error {
+	_, err := db.Exec(`
+		UPDATE versions SET git_sha = ?
+		WHERE git_url = ? AND component = ? AND tag_name = ?
+	`, sha, gitURL, component, tagName)
+	if err != nil {
+		return fmt.Errorf("failed to update version: %w", err)
+	}
+	return nil
+}
+
+// Reconciliation actions taken by qv vet
+const (
+	ActionImport        = "import"         // add a remote-only tag to qv.db
+	ActionDeleteLocal   = "delete_local"   // delete a local-only version from qv.db
+	ActionUpdateLocal   = "update_local"   // point a version in qv.db at the remote tag's commit
+	ActionCreateRemote  = "create_remote"  // recreate a missing remote tag from qv.db
+	ActionRepointRemote = "repoint_remote" // move a remote tag to the commit in qv.db
+)
+
+// RecordReconciliation adds an entry to the reconciliation audit trail
+func (db *DB) RecordReconciliation(r *Reconciliation) error {
+	_, err := db.Exec(`
+		INSERT INTO reconciliations (git_url, component, tag_name, action, strategy, old_sha, new_sha, actor, hostname, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, r.GitURL, r.Component, r.TagName, r.Action, r.Strategy, r.OldSHA, r.NewSHA, r.Actor, r.Hostname, r.Error)
+	if err != nil {
+		return fmt.Errorf("failed to record reconciliation: %w", err)
+	}
+	return nil
+}
+
+// GetReconciliations returns the audit trail of a component, newest first.
+// A limit of 0 returns every entry.
+func (db *DB) GetReconciliations(gitURL, component string, limit int) ([]Reconciliation, error) {
+	query := `
+		SELECT id, git_url, component, tag_name, action, strategy, old_sha, new_sha, actor, hostname, performed_at, error
+		FROM reconciliations
+		WHERE git_url = ? AND component = ?
+		ORDER BY id DESC`
+	args := []any{gitURL, component}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reconciliations: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []Reconciliation
+	for rows.Next() {
+		var r Reconciliation
+		if err := rows.Scan(&r.ID, &r.GitURL, &r.Component, &r.TagName, &r.Action, &r.Strategy, &r.OldSHA, &r.NewSHA, &r.Actor, &r.Hostname, &r.PerformedAt, &r.Error); err != nil {
+			return nil, fmt.Errorf("failed to scan reconciliation: %w", err)
+		}
+		entries = append(entries, r)
+	}
+	return entries, nil
+}
+
+// Reconciliation records one action qv vet took to bring qv.db and the
+// remote tags back in sync
+type Reconciliation struct {
+	ID          int
+	GitURL      string
+	Component   string
+	TagName     string
+	Action      string
+	Strategy    string  // remote-wins, local-wins or interactive
+	OldSHA      *string // commit before the action, nil if there was none
+	NewSHA      *string // commit after the action, nil if the tag or row was deleted
+	Actor       *string
+	Hostname    *string
+	PerformedAt string
+	Error       *string
+}
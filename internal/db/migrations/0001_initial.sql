@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS versions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    version TEXT NOT NULL,
+    tag_name TEXT NOT NULL,
+    git_sha TEXT NOT NULL,
+    git_url TEXT NOT NULL,
+    increment_type TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(git_url, version)
+);
+
+CREATE TABLE IF NOT EXISTS config_state (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    last_synced_at TIMESTAMP,
+    git_url TEXT NOT NULL
+);
@@ -0,0 +1 @@
+ALTER TABLE versions ADD COLUMN channel TEXT;
@@ -0,0 +1,2 @@
+ALTER TABLE versions ADD COLUMN release_id INTEGER;
+ALTER TABLE versions ADD COLUMN release_url TEXT;
@@ -0,0 +1,26 @@
+-- SQLite cannot change the UNIQUE(git_url, version) constraint in place,
+-- so the versions table is rebuilt with the component column
+ALTER TABLE versions RENAME TO versions_old;
+
+CREATE TABLE versions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    version TEXT NOT NULL,
+    tag_name TEXT NOT NULL,
+    git_sha TEXT NOT NULL,
+    git_url TEXT NOT NULL,
+    component TEXT NOT NULL DEFAULT '',
+    increment_type TEXT,
+    channel TEXT,
+    release_id INTEGER,
+    release_url TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(git_url, component, version)
+);
+
+INSERT INTO versions (id, version, tag_name, git_sha, git_url, increment_type, channel, release_id, release_url, created_at)
+SELECT id, version, tag_name, git_sha, git_url, increment_type, channel, release_id, release_url, created_at
+FROM versions_old;
+
+DROP TABLE versions_old;
+
+ALTER TABLE images ADD COLUMN component TEXT NOT NULL DEFAULT '';
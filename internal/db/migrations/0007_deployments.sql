@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS deployments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    git_url TEXT NOT NULL,
+    component TEXT NOT NULL DEFAULT '',
+    version TEXT NOT NULL,
+    tag_name TEXT NOT NULL,
+    actor TEXT,
+    hostname TEXT,
+    plan_hash TEXT,
+    started_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP,
+    outcome TEXT NOT NULL DEFAULT 'running',
+    error TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_deployments_version ON deployments (git_url, component, version);
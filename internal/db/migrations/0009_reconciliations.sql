@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS reconciliations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    git_url TEXT NOT NULL,
+    component TEXT NOT NULL DEFAULT '',
+    tag_name TEXT NOT NULL,
+    action TEXT NOT NULL,
+    strategy TEXT NOT NULL,
+    old_sha TEXT,
+    new_sha TEXT,
+    actor TEXT,
+    hostname TEXT,
+    performed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    error TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_reconciliations_component ON reconciliations (git_url, component);
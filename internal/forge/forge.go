@@ -0,0 +1,303 @@
+package forge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/excircle/quik-version/internal/config"
+	"github.com/excircle/quik-version/internal/prompt"
+)
+
+// Supported forge kinds
+const (
+	GitHub = "github"
+	GitLab = "gitlab"
+	Gitea  = "gitea"
+	Git    = "git"
+)
+
+// Forge is a code hosting service that qv can read tags and commits from
+// and publish tags, pull requests and releases to. Each Forge is bound to
+// a single repository.
+type Forge interface {
+	// Kind returns the forge kind (github, gitlab, gitea or git)
+	Kind() string
+	// RepoPath returns the repository path on the forge (e.g. owner/repo)
+	RepoPath() string
+	// CurrentUser returns the login of the authenticated user
+	CurrentUser(ctx context.Context) (string, error)
+	// ListTags fetches all tags with the commit SHA they point to
+	ListTags(ctx context.Context) ([]Tag, error)
+	// GetLatestCommitSHA gets the SHA of the latest commit on a branch
+	GetLatestCommitSHA(ctx context.Context, branch string) (string, error)
+	// ResolveCommit returns the full SHA of the commit a ref (branch, tag or
+	// full or abbreviated SHA) points to, or an error if it does not exist
+	ResolveCommit(ctx context.Context, ref string) (string, error)
+	// CreateTag creates an annotated tag on a specific commit
+	CreateTag(ctx context.Context, tagName, commitSHA, message string) error
+	// DeleteTag deletes a tag ref from the repository
+	DeleteTag(ctx context.Context, tagName string) error
+	// CreatePR creates a pull (or merge) request from head to base, as a
+	// draft if draft is set
+	CreatePR(ctx context.Context, title, body, head, base string, draft bool) (*PullRequest, error)
+	// CreateRelease publishes a release for an existing tag
+	CreateRelease(ctx context.Context, tagName, name, body string, draft, prerelease bool) (*Release, error)
+	// CompareCommits lists commits reachable from head but not base, oldest first
+	CompareCommits(ctx context.Context, base, head string) ([]Commit, error)
+	// ListCommits lists every commit reachable from ref, oldest first
+	ListCommits(ctx context.Context, ref string) ([]Commit, error)
+	// CommitFiles lists the paths changed by a commit
+	CommitFiles(ctx context.Context, sha string) ([]string, error)
+}
+
+// TagObjectWriter is implemented by forges that create an annotated tag
+// object and the ref pointing to it in separate steps. CreateTag on such
+// forges is CreateTagObject followed by CreateTagRef.
+type TagObjectWriter interface {
+	// CreateTagObject creates an annotated tag object for a commit and
+	// returns the object's SHA
+	CreateTagObject(ctx context.Context, tagName, commitSHA, message string) (string, error)
+	// CreateTagRef creates refs/tags/<tagName> pointing to a tag object
+	CreateTagRef(ctx context.Context, tagName, objectSHA string) error
+}
+
+// BranchWriter is implemented by forges that can read files, create
+// branches and commit to them through their API, without a local clone
+type BranchWriter interface {
+	// GetFile returns the content of a file at a ref, or ErrFileNotFound
+	GetFile(ctx context.Context, path, ref string) ([]byte, error)
+	// CreateBranch creates a branch pointing to a commit
+	CreateBranch(ctx context.Context, branch, sha string) error
+	// ResetBranch points a branch at a commit, creating the branch if it
+	// does not exist and discarding commits that are only on the branch
+	ResetBranch(ctx context.Context, branch, sha string) error
+	// CommitChanges commits new file contents to a branch as a single
+	// commit and returns the commit's SHA
+	CommitChanges(ctx context.Context, branch, message string, files []FileChange) (string, error)
+}
+
+// PullRequestEditor is implemented by forges that can find and edit
+// existing pull requests, e.g. to maintain a standing release PR
+type PullRequestEditor interface {
+	// FindPR returns the open pull request from head to base, or nil
+	FindPR(ctx context.Context, head, base string) (*PullRequest, error)
+	// ListPRs lists the pull requests carrying a label in a state
+	// (PRStateOpen or PRStateMerged), most recently updated first
+	ListPRs(ctx context.Context, label, state string) ([]PullRequest, error)
+	// UpdatePR replaces the title and body of a pull request and reopens
+	// it if the forge closed it
+	UpdatePR(ctx context.Context, number int, title, body string) error
+	// SetPROptions adds labels, reviewers and assignees to a pull request
+	// and sets its milestone. Missing labels are created.
+	SetPROptions(ctx context.Context, number int, opts PROptions) error
+}
+
+// CommitPRLister is implemented by forges that can look up the pull
+// requests a commit belongs to
+type CommitPRLister interface {
+	// CommitPRs lists the pull requests that contain a commit, open or
+	// merged
+	CommitPRs(ctx context.Context, sha string) ([]PullRequest, error)
+}
+
+// PROptions are the optional settings of a pull request. Empty fields
+// are left unchanged.
+type PROptions struct {
+	Labels    []string
+	Reviewers []string // user logins, or org/team slugs on GitHub
+	Assignees []string
+	Milestone string // milestone title
+}
+
+// IsEmpty reports whether no option is set
+func (o PROptions) IsEmpty() bool {
+	return len(o.Labels) == 0 && len(o.Reviewers) == 0 && len(o.Assignees) == 0 && o.Milestone == ""
+}
+
+// Pull request states for PullRequestEditor.ListPRs
+const (
+	PRStateOpen   = "open"
+	PRStateMerged = "merged"
+)
+
+// ErrFileNotFound is returned by BranchWriter.GetFile for missing files
+var ErrFileNotFound = errors.New("file not found")
+
+// FileChange is the new content of a file in a commit
+type FileChange struct {
+	Path    string
+	Content []byte
+}
+
+// Tag represents a tag and the commit it points to
+type Tag struct {
+	Name string
+	SHA  string
+}
+
+// Commit represents a commit in a repository's history
+type Commit struct {
+	SHA     string
+	Message string
+}
+
+// PullRequest represents a pull or merge request
+type PullRequest struct {
+	Number int
+	URL    string
+	Title  string
+	Body   string
+	Head   string
+	Base   string
+	Labels []string
+	Merged bool
+	// MergeCommitSHA is the commit the pull request was merged as, empty
+	// until it is merged
+	MergeCommitSHA string
+}
+
+// Release represents a published release
+type Release struct {
+	ID  int64
+	URL string
+}
+
+// Detect returns the forge kind for a git URL. The version.forge config
+// key takes precedence over detection from the host name.
+func Detect(gitURL string) (string, error) {
+	if kind := config.GetForge(); kind != "" {
+		switch kind {
+		case GitHub, GitLab, Gitea, Git:
+			return kind, nil
+		default:
+			return "", fmt.Errorf("unsupported forge %q (expected github, gitlab, gitea or git)", kind)
+		}
+	}
+
+	// Repositories on the local filesystem can only be reached with git
+	if strings.HasPrefix(gitURL, "file://") || filepath.IsAbs(gitURL) {
+		return Git, nil
+	}
+
+	host, _, err := ParseRepoURL(gitURL)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case host == "github.com":
+		return GitHub, nil
+	case config.GetAPIURL() != "":
+		// A custom API URL on an unknown host is a GitHub Enterprise Server
+		return GitHub, nil
+	case host == "gitlab.com" || strings.Contains(host, "gitlab"):
+		return GitLab, nil
+	case host == "codeberg.org" || strings.Contains(host, "gitea"):
+		return Gitea, nil
+	default:
+		return "", fmt.Errorf("cannot detect forge for host %s, set version.forge in %s", host, "quik.conf")
+	}
+}
+
+// ParseRepoURL splits a repository URL into its host and repository path.
+// Supported forms are https://host/org/repo, http://host/org/repo,
+// ssh://[user@]host[:port]/org/repo and the scp-like user@host:org/repo,
+// each with or without a .git suffix. Ports are dropped from the host.
+func ParseRepoURL(url string) (host, path string, err error) {
+	original := url
+	url = strings.TrimSuffix(url, "/")
+	url = strings.TrimSuffix(url, ".git")
+
+	switch {
+	case strings.HasPrefix(url, "https://"):
+		url = strings.TrimPrefix(url, "https://")
+	case strings.HasPrefix(url, "http://"):
+		url = strings.TrimPrefix(url, "http://")
+	case strings.HasPrefix(url, "ssh://"):
+		url = strings.TrimPrefix(url, "ssh://")
+	case strings.Contains(url, "@") && strings.Contains(url, ":"):
+		// scp-like syntax: user@host:org/repo
+		url = strings.Replace(url, ":", "/", 1)
+	default:
+		return "", "", fmt.Errorf("invalid repository URL format: %s", original)
+	}
+
+	host, path, ok := strings.Cut(url, "/")
+	if _, after, found := strings.Cut(host, "@"); found {
+		host = after
+	}
+	if h, _, found := strings.Cut(host, ":"); found {
+		host = h
+	}
+
+	if !ok || host == "" || !strings.Contains(path, "/") || strings.HasPrefix(path, "/") {
+		return "", "", fmt.Errorf("invalid repository URL format: %s", original)
+	}
+
+	return host, path, nil
+}
+
+// APIBaseURL returns the configured API base URL, or the default for the
+// host built from defaultPath (e.g. "/api/v4")
+func APIBaseURL(gitURL, host, defaultPath string) string {
+	if apiURL := config.GetAPIURL(); apiURL != "" {
+		return strings.TrimSuffix(apiURL, "/")
+	}
+
+	scheme := "https://"
+	if strings.HasPrefix(gitURL, "http://") {
+		scheme = "http://"
+	}
+	return scheme + host + defaultPath
+}
+
+// ResolveToken retrieves an API token using the auth flow:
+// 1. Check the forge-specific environment variable (e.g. GITLAB_TOKEN)
+// 2. Check if Token is defined in quik.conf
+// 3. Interactive prompt - ask user for Token (fails without a terminal)
+func ResolveToken(envVar, forgeName string) (string, error) {
+	if token := os.Getenv(envVar); token != "" {
+		return token, nil
+	}
+
+	if token := config.GetToken(); token != "" {
+		return token, nil
+	}
+
+	return promptForToken(envVar, forgeName)
+}
+
+func promptForToken(envVar, forgeName string) (string, error) {
+	token, err := prompt.String(fmt.Sprintf("Enter %s token: ", forgeName))
+	if errors.Is(err, prompt.ErrNoInput) {
+		return "", fmt.Errorf("no %s token found: set %s or version.token in quik.conf", forgeName, envVar)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read token: %w", err)
+	}
+
+	if token == "" {
+		return "", fmt.Errorf("token cannot be empty")
+	}
+
+	return token, nil
+}
+
+// TokenEnvVar returns the environment variable holding the API token for a
+// forge kind, or an empty string for forges that need no token
+func TokenEnvVar(kind string) string {
+	switch kind {
+	case GitHub:
+		return "GITHUB_TOKEN"
+	case GitLab:
+		return "GITLAB_TOKEN"
+	case Gitea:
+		return "GITEA_TOKEN"
+	default:
+		return ""
+	}
+}
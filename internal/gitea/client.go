@@ -0,0 +1,608 @@
+package gitea
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/excircle/quik-version/internal/forge"
+)
+
+// pageSize is the number of items requested per page
+const pageSize = 50
+
+// Client is a Gitea REST API (v1) client bound to a single repository and
+// implements forge.Forge
+type Client struct {
+	api   *forge.RESTClient
+	owner string
+	repo  string
+}
+
+// NewClient creates a Gitea client for a repository URL. The token is taken
+// from GITEA_TOKEN, the config file, or an interactive prompt.
+func NewClient(ctx context.Context, gitURL string) (*Client, error) {
+	host, path, err := forge.ParseRepoURL(gitURL)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, repo, ok := strings.Cut(path, "/")
+	if !ok || strings.Contains(repo, "/") {
+		return nil, fmt.Errorf("invalid Gitea URL format")
+	}
+
+	token, err := forge.ResolveToken("GITEA_TOKEN", "Gitea")
+	if err != nil {
+		return nil, err
+	}
+
+	header := http.Header{}
+	header.Set("Authorization", "token "+token)
+
+	return &Client{
+		api: &forge.RESTClient{
+			BaseURL: forge.APIBaseURL(gitURL, host, "/api/v1"),
+			Header:  header,
+		},
+		owner: owner,
+		repo:  repo,
+	}, nil
+}
+
+// Kind returns the forge kind
+func (c *Client) Kind() string {
+	return forge.Gitea
+}
+
+// RepoPath returns owner/repo
+func (c *Client) RepoPath() string {
+	return c.owner + "/" + c.repo
+}
+
+// repoPath returns the API path prefix for the repository
+func (c *Client) repoPath() string {
+	return "/repos/" + url.PathEscape(c.owner) + "/" + url.PathEscape(c.repo)
+}
+
+// CurrentUser returns the login of the user the token belongs to
+func (c *Client) CurrentUser(ctx context.Context) (string, error) {
+	var user struct {
+		Login string `json:"login"`
+	}
+	if _, err := c.api.Do(ctx, http.MethodGet, "/user", nil, &user); err != nil {
+		return "", fmt.Errorf("failed to get authenticated user: %w", err)
+	}
+	return user.Login, nil
+}
+
+type giteaCommit struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Message string `json:"message"`
+	} `json:"commit"`
+}
+
+// ListTags fetches all tags from the repository
+func (c *Client) ListTags(ctx context.Context) ([]forge.Tag, error) {
+	var allTags []forge.Tag
+
+	for page := 1; ; page++ {
+		var tags []struct {
+			Name   string `json:"name"`
+			Commit struct {
+				SHA string `json:"sha"`
+			} `json:"commit"`
+		}
+		path := fmt.Sprintf("%s/tags?limit=%d&page=%d", c.repoPath(), pageSize, page)
+		if _, err := c.api.Do(ctx, http.MethodGet, path, nil, &tags); err != nil {
+			return nil, fmt.Errorf("failed to list tags: %w", err)
+		}
+
+		for _, tag := range tags {
+			allTags = append(allTags, forge.Tag{Name: tag.Name, SHA: tag.Commit.SHA})
+		}
+		if len(tags) < pageSize {
+			break
+		}
+	}
+
+	return allTags, nil
+}
+
+// GetLatestCommitSHA gets the SHA of the latest commit on a branch
+func (c *Client) GetLatestCommitSHA(ctx context.Context, branch string) (string, error) {
+	var result struct {
+		Commit struct {
+			ID string `json:"id"`
+		} `json:"commit"`
+	}
+	path := fmt.Sprintf("%s/branches/%s", c.repoPath(), url.PathEscape(branch))
+	if _, err := c.api.Do(ctx, http.MethodGet, path, nil, &result); err != nil {
+		return "", fmt.Errorf("failed to get branch ref: %w", err)
+	}
+	return result.Commit.ID, nil
+}
+
+// ResolveCommit returns the full SHA of the commit a ref points to
+func (c *Client) ResolveCommit(ctx context.Context, ref string) (string, error) {
+	var commits []giteaCommit
+	path := fmt.Sprintf("%s/commits?sha=%s&limit=1&stat=false", c.repoPath(), url.QueryEscape(ref))
+	if _, err := c.api.Do(ctx, http.MethodGet, path, nil, &commits); err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	if len(commits) == 0 {
+		return "", fmt.Errorf("failed to resolve %s: no such commit", ref)
+	}
+	return commits[0].SHA, nil
+}
+
+// CreateTag creates an annotated tag on a specific commit
+func (c *Client) CreateTag(ctx context.Context, tagName, commitSHA, message string) error {
+	body := map[string]string{
+		"tag_name": tagName,
+		"target":   commitSHA,
+		"message":  message,
+	}
+	if _, err := c.api.Do(ctx, http.MethodPost, c.repoPath()+"/tags", body, nil); err != nil {
+		return fmt.Errorf("failed to create tag: %w", err)
+	}
+	return nil
+}
+
+// DeleteTag deletes a tag from the repository
+func (c *Client) DeleteTag(ctx context.Context, tagName string) error {
+	path := c.repoPath() + "/tags/" + url.PathEscape(tagName)
+	if _, err := c.api.Do(ctx, http.MethodDelete, path, nil, nil); err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+	return nil
+}
+
+// wipPrefix marks a pull request as work in progress (draft) in its title
+const wipPrefix = "WIP: "
+
+// CreatePR creates a pull request from head branch to base branch
+func (c *Client) CreatePR(ctx context.Context, title, body, head, base string, draft bool) (*forge.PullRequest, error) {
+	if draft {
+		title = wipPrefix + title
+	}
+	req := map[string]string{
+		"title": title,
+		"body":  body,
+		"head":  head,
+		"base":  base,
+	}
+	var created giteaPull
+	if _, err := c.api.Do(ctx, http.MethodPost, c.repoPath()+"/pulls", req, &created); err != nil {
+		return nil, fmt.Errorf("failed to create pull request: %w", err)
+	}
+
+	pr := created.toPullRequest()
+	return &pr, nil
+}
+
+// giteaPull is a pull request as returned by the API
+type giteaPull struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	Head    struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+	Labels []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	State          string `json:"state"`
+	Merged         bool   `json:"merged"`
+	MergeCommitSHA string `json:"merge_commit_sha"`
+}
+
+// toPullRequest converts a Gitea pull request
+func (p giteaPull) toPullRequest() forge.PullRequest {
+	pr := forge.PullRequest{
+		Number:         p.Number,
+		URL:            p.HTMLURL,
+		Title:          p.Title,
+		Body:           p.Body,
+		Head:           p.Head.Ref,
+		Base:           p.Base.Ref,
+		Merged:         p.Merged,
+		MergeCommitSHA: p.MergeCommitSHA,
+	}
+	for _, l := range p.Labels {
+		pr.Labels = append(pr.Labels, l.Name)
+	}
+	return pr
+}
+
+// CommitPRs lists the pull request that introduced a commit. Gitea
+// reports at most one.
+func (c *Client) CommitPRs(ctx context.Context, sha string) ([]forge.PullRequest, error) {
+	var pull giteaPull
+	path := fmt.Sprintf("%s/commits/%s/pull", c.repoPath(), url.PathEscape(sha))
+	resp, err := c.api.Do(ctx, http.MethodGet, path, nil, &pull)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request of commit %s: %w", sha, err)
+	}
+	return []forge.PullRequest{pull.toPullRequest()}, nil
+}
+
+// giteaLabel is a repository label
+type giteaLabel struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// labelIDs returns the IDs of the named labels. Missing labels are
+// created when create is set and left out otherwise.
+func (c *Client) labelIDs(ctx context.Context, names []string, create bool) ([]int64, error) {
+	var labels []giteaLabel
+	if _, err := c.api.Do(ctx, http.MethodGet, c.repoPath()+"/labels?limit=100", nil, &labels); err != nil {
+		return nil, fmt.Errorf("failed to list labels: %w", err)
+	}
+
+	var ids []int64
+	for _, name := range names {
+		found := false
+		for _, l := range labels {
+			if l.Name == name {
+				ids = append(ids, l.ID)
+				found = true
+				break
+			}
+		}
+		if found || !create {
+			continue
+		}
+
+		var created giteaLabel
+		req := map[string]string{"name": name, "color": "#ededed"}
+		if _, err := c.api.Do(ctx, http.MethodPost, c.repoPath()+"/labels", req, &created); err != nil {
+			return nil, fmt.Errorf("failed to create label %s: %w", name, err)
+		}
+		ids = append(ids, created.ID)
+	}
+	return ids, nil
+}
+
+// FindPR returns the open pull request from head branch to base branch,
+// or nil if there is none
+func (c *Client) FindPR(ctx context.Context, head, base string) (*forge.PullRequest, error) {
+	var pull giteaPull
+	path := fmt.Sprintf("%s/pulls/%s/%s", c.repoPath(), url.PathEscape(base), url.PathEscape(head))
+	resp, err := c.api.Do(ctx, http.MethodGet, path, nil, &pull)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request: %w", err)
+	}
+	if pull.State != "open" {
+		return nil, nil
+	}
+
+	pr := pull.toPullRequest()
+	return &pr, nil
+}
+
+// ListPRs lists up to 50 pull requests carrying a label, most recently
+// updated first. State is forge.PRStateOpen or forge.PRStateMerged.
+func (c *Client) ListPRs(ctx context.Context, label, state string) ([]forge.PullRequest, error) {
+	ids, err := c.labelIDs(ctx, []string{label}, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil // No pull request can carry a label that does not exist
+	}
+
+	pullState := "open"
+	if state == forge.PRStateMerged {
+		pullState = "closed"
+	}
+
+	var pulls []giteaPull
+	path := fmt.Sprintf("%s/pulls?state=%s&labels=%d&sort=recentupdate&limit=50", c.repoPath(), pullState, ids[0])
+	if _, err := c.api.Do(ctx, http.MethodGet, path, nil, &pulls); err != nil {
+		return nil, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+
+	var prs []forge.PullRequest
+	for _, p := range pulls {
+		if state == forge.PRStateMerged && !p.Merged {
+			continue // Closed without merging
+		}
+		prs = append(prs, p.toPullRequest())
+	}
+	return prs, nil
+}
+
+// UpdatePR replaces the title and body of a pull request. A work in
+// progress pull request keeps its prefix. Gitea closes pull requests whose
+// head branch is deleted, so the pull request is reopened as well.
+func (c *Client) UpdatePR(ctx context.Context, number int, title, body string) error {
+	path := fmt.Sprintf("%s/pulls/%d", c.repoPath(), number)
+
+	var current giteaPull
+	if _, err := c.api.Do(ctx, http.MethodGet, path, nil, &current); err != nil {
+		return fmt.Errorf("failed to get pull request #%d: %w", number, err)
+	}
+	if strings.HasPrefix(current.Title, strings.TrimSpace(wipPrefix)) {
+		title = wipPrefix + title
+	}
+
+	req := map[string]string{
+		"title": title,
+		"body":  body,
+		"state": "open",
+	}
+	if _, err := c.api.Do(ctx, http.MethodPatch, path, req, nil); err != nil {
+		return fmt.Errorf("failed to update pull request #%d: %w", number, err)
+	}
+	return nil
+}
+
+// SetPROptions adds labels and reviewers to a pull request, sets its
+// assignees and its milestone. Missing labels are created.
+func (c *Client) SetPROptions(ctx context.Context, number int, opts forge.PROptions) error {
+	if len(opts.Labels) > 0 {
+		ids, err := c.labelIDs(ctx, opts.Labels, true)
+		if err != nil {
+			return err
+		}
+		req := map[string][]int64{"labels": ids}
+		path := fmt.Sprintf("%s/issues/%d/labels", c.repoPath(), number)
+		if _, err := c.api.Do(ctx, http.MethodPost, path, req, nil); err != nil {
+			return fmt.Errorf("failed to label pull request #%d: %w", number, err)
+		}
+	}
+
+	if len(opts.Reviewers) > 0 {
+		req := map[string][]string{"reviewers": opts.Reviewers}
+		path := fmt.Sprintf("%s/pulls/%d/requested_reviewers", c.repoPath(), number)
+		if _, err := c.api.Do(ctx, http.MethodPost, path, req, nil); err != nil {
+			return fmt.Errorf("failed to request reviewers for pull request #%d: %w", number, err)
+		}
+	}
+
+	// Assignees and the milestone are issue fields
+	req := map[string]any{}
+	if len(opts.Assignees) > 0 {
+		req["assignees"] = opts.Assignees
+	}
+	if opts.Milestone != "" {
+		id, err := c.milestoneID(ctx, opts.Milestone)
+		if err != nil {
+			return err
+		}
+		req["milestone"] = id
+	}
+	if len(req) > 0 {
+		path := fmt.Sprintf("%s/issues/%d", c.repoPath(), number)
+		if _, err := c.api.Do(ctx, http.MethodPatch, path, req, nil); err != nil {
+			return fmt.Errorf("failed to update pull request #%d: %w", number, err)
+		}
+	}
+
+	return nil
+}
+
+// milestoneID returns the ID of the open milestone with a title
+func (c *Client) milestoneID(ctx context.Context, title string) (int64, error) {
+	var milestones []struct {
+		ID    int64  `json:"id"`
+		Title string `json:"title"`
+	}
+	path := fmt.Sprintf("%s/milestones?state=open&name=%s", c.repoPath(), url.QueryEscape(title))
+	if _, err := c.api.Do(ctx, http.MethodGet, path, nil, &milestones); err != nil {
+		return 0, fmt.Errorf("failed to list milestones: %w", err)
+	}
+	for _, m := range milestones {
+		if m.Title == title {
+			return m.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("milestone %q not found", title)
+}
+
+// CreateRelease publishes a release for an existing tag
+func (c *Client) CreateRelease(ctx context.Context, tagName, name, body string, draft, prerelease bool) (*forge.Release, error) {
+	req := map[string]any{
+		"tag_name":   tagName,
+		"name":       name,
+		"body":       body,
+		"draft":      draft,
+		"prerelease": prerelease,
+	}
+	var created struct {
+		ID      int64  `json:"id"`
+		HTMLURL string `json:"html_url"`
+	}
+	if _, err := c.api.Do(ctx, http.MethodPost, c.repoPath()+"/releases", req, &created); err != nil {
+		return nil, fmt.Errorf("failed to create release: %w", err)
+	}
+
+	return &forge.Release{ID: created.ID, URL: created.HTMLURL}, nil
+}
+
+// CompareCommits lists commits reachable from head but not base, oldest first
+func (c *Client) CompareCommits(ctx context.Context, base, head string) ([]forge.Commit, error) {
+	var result struct {
+		Commits []giteaCommit `json:"commits"`
+	}
+	path := fmt.Sprintf("%s/compare/%s...%s", c.repoPath(), url.PathEscape(base), url.PathEscape(head))
+	if _, err := c.api.Do(ctx, http.MethodGet, path, nil, &result); err != nil {
+		return nil, fmt.Errorf("failed to compare commits: %w", err)
+	}
+
+	// Gitea lists the compared commits newest first, like git log
+	n := len(result.Commits)
+	commits := make([]forge.Commit, n)
+	for i, commit := range result.Commits {
+		commits[n-1-i] = forge.Commit{SHA: commit.SHA, Message: commit.Commit.Message}
+	}
+	return commits, nil
+}
+
+// ListCommits lists every commit reachable from ref, oldest first
+func (c *Client) ListCommits(ctx context.Context, ref string) ([]forge.Commit, error) {
+	var allCommits []forge.Commit
+
+	for page := 1; ; page++ {
+		var commits []giteaCommit
+		path := fmt.Sprintf("%s/commits?sha=%s&limit=%d&page=%d", c.repoPath(), url.QueryEscape(ref), pageSize, page)
+		if _, err := c.api.Do(ctx, http.MethodGet, path, nil, &commits); err != nil {
+			return nil, fmt.Errorf("failed to list commits: %w", err)
+		}
+
+		for _, commit := range commits {
+			allCommits = append(allCommits, forge.Commit{SHA: commit.SHA, Message: commit.Commit.Message})
+		}
+		if len(commits) < pageSize {
+			break
+		}
+	}
+
+	// The API returns newest first
+	forge.Reverse(allCommits)
+	return allCommits, nil
+}
+
+// CommitFiles lists the paths changed by a commit
+func (c *Client) CommitFiles(ctx context.Context, sha string) ([]string, error) {
+	var result struct {
+		Files []struct {
+			Filename string `json:"filename"`
+		} `json:"files"`
+	}
+	path := fmt.Sprintf("%s/git/commits/%s?files=true", c.repoPath(), url.PathEscape(sha))
+	if _, err := c.api.Do(ctx, http.MethodGet, path, nil, &result); err != nil {
+		return nil, fmt.Errorf("failed to get commit: %w", err)
+	}
+
+	files := make([]string, len(result.Files))
+	for i, f := range result.Files {
+		files[i] = f.Filename
+	}
+	return files, nil
+}
+
+// giteaContent is a file returned by the contents API
+type giteaContent struct {
+	Type    string `json:"type"`
+	SHA     string `json:"sha"`
+	Content string `json:"content"`
+}
+
+// getContent returns a file from the contents API at a ref
+func (c *Client) getContent(ctx context.Context, filePath, ref string) (*giteaContent, error) {
+	segments := strings.Split(filePath, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	var file giteaContent
+	path := fmt.Sprintf("%s/contents/%s?ref=%s", c.repoPath(), strings.Join(segments, "/"), url.QueryEscape(ref))
+	resp, err := c.api.Do(ctx, http.MethodGet, path, nil, &file)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil, forge.ErrFileNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", filePath, err)
+	}
+	if file.Type != "file" {
+		return nil, fmt.Errorf("%s is not a file", filePath)
+	}
+	return &file, nil
+}
+
+// GetFile returns the content of a file at a ref
+func (c *Client) GetFile(ctx context.Context, filePath, ref string) ([]byte, error) {
+	file, err := c.getContent(ctx, filePath, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := base64.StdEncoding.DecodeString(file.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", filePath, err)
+	}
+	return content, nil
+}
+
+// CreateBranch creates a branch pointing to a commit
+func (c *Client) CreateBranch(ctx context.Context, branch, sha string) error {
+	body := map[string]string{
+		"new_branch_name": branch,
+		"old_ref_name":    sha,
+	}
+	if _, err := c.api.Do(ctx, http.MethodPost, c.repoPath()+"/branches", body, nil); err != nil {
+		return fmt.Errorf("failed to create branch %s: %w", branch, err)
+	}
+	return nil
+}
+
+// ResetBranch points a branch at a commit by deleting and recreating it
+func (c *Client) ResetBranch(ctx context.Context, branch, sha string) error {
+	path := fmt.Sprintf("%s/branches/%s", c.repoPath(), url.PathEscape(branch))
+	resp, err := c.api.Do(ctx, http.MethodDelete, path, nil, nil)
+	if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+		return fmt.Errorf("failed to reset branch %s: %w", branch, err)
+	}
+	return c.CreateBranch(ctx, branch, sha)
+}
+
+// CommitChanges commits files to a branch as a single commit with the
+// change-files API. Existing files are updated by their blob SHA.
+func (c *Client) CommitChanges(ctx context.Context, branch, message string, files []forge.FileChange) (string, error) {
+	type fileOperation struct {
+		Operation string `json:"operation"`
+		Path      string `json:"path"`
+		Content   string `json:"content"`
+		SHA       string `json:"sha,omitempty"`
+	}
+
+	operations := make([]fileOperation, len(files))
+	for i, f := range files {
+		op := fileOperation{
+			Operation: "create",
+			Path:      f.Path,
+			Content:   base64.StdEncoding.EncodeToString(f.Content),
+		}
+		existing, err := c.getContent(ctx, f.Path, branch)
+		if err == nil {
+			op.Operation = "update"
+			op.SHA = existing.SHA
+		} else if !errors.Is(err, forge.ErrFileNotFound) {
+			return "", err
+		}
+		operations[i] = op
+	}
+
+	body := map[string]any{
+		"branch":  branch,
+		"message": message,
+		"files":   operations,
+	}
+	var created struct {
+		Commit struct {
+			SHA string `json:"sha"`
+		} `json:"commit"`
+	}
+	if _, err := c.api.Do(ctx, http.MethodPost, c.repoPath()+"/contents", body, &created); err != nil {
+		return "", fmt.Errorf("failed to create commit: %w", err)
+	}
+	return created.Commit.SHA, nil
+}
@@ -0,0 +1,56 @@
+package gitea
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/excircle/quik-version/internal/forge"
+)
+
+// newTestClient returns a client for acme/app on a test server
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return &Client{
+		api:   &forge.RESTClient{BaseURL: server.URL + "/api/v1", HTTP: server.Client()},
+		owner: "acme",
+		repo:  "app",
+	}
+}
+
+func TestCompareCommits(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/acme/app/compare/v1.0.0...main" {
+			http.NotFound(w, r)
+			return
+		}
+		// The compare API lists commits newest first
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"total_commits": 3, "commits": [
+			{"sha": "ccc", "commit": {"message": "fix: third"}},
+			{"sha": "bbb", "commit": {"message": "feat: second"}},
+			{"sha": "aaa", "commit": {"message": "fix: first"}}
+		]}`))
+	})
+
+	commits, err := client.CompareCommits(context.Background(), "v1.0.0", "main")
+	if err != nil {
+		t.Fatalf("CompareCommits: %v", err)
+	}
+	want := []forge.Commit{
+		{SHA: "aaa", Message: "fix: first"},
+		{SHA: "bbb", Message: "feat: second"},
+		{SHA: "ccc", Message: "fix: third"},
+	}
+	if len(commits) != len(want) {
+		t.Fatalf("CompareCommits = %v, want %v", commits, want)
+	}
+	for i := range want {
+		if commits[i] != want[i] {
+			t.Errorf("commit %d = %+v, want %+v (oldest first)", i, commits[i], want[i])
+		}
+	}
+}
@@ -1,16 +1,16 @@
 package github
 
 import (
-	"bufio"
 	"context"
 	"fmt"
-	"os"
+	"net/http"
 	"strings"
 
 	"github.com/google/go-github/v80/github"
 	"golang.org/x/oauth2"
 
 	"github.com/excircle/quik-version/internal/config"
+	"github.com/excircle/quik-version/internal/forge"
 )
 
 // Client wraps the GitHub client with authentication
@@ -23,6 +23,9 @@ type Client struct {
 // 1. Check for GITHUB_TOKEN environment variable
 // 2. Check if Token is defined in quik.conf
 // 3. Interactive prompt - ask user for Token
+//
+// If version.api_url is configured the client targets that GitHub
+// Enterprise Server instead of api.github.com.
 func NewClient(ctx context.Context) (*Client, error) {
 	token, err := getToken()
 	if err != nil {
@@ -36,42 +39,33 @@ func NewClient(ctx context.Context) (*Client, error) {
 
 	client := github.NewClient(tc)
 
+	if apiURL := config.GetAPIURL(); apiURL != "" {
+		uploadURL := config.GetUploadURL()
+		if uploadURL == "" {
+			uploadURL = defaultUploadURL(apiURL)
+		}
+		client, err = client.WithEnterpriseURLs(apiURL, uploadURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GitHub Enterprise URL: %w", err)
+		}
+	}
+
 	return &Client{
 		Client: client,
 		token:  token,
 	}, nil
 }
 
-// getToken retrieves the GitHub token using the auth flow
-func getToken() (string, error) {
-	// 1. Check GITHUB_TOKEN environment variable
-	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
-		return token, nil
-	}
-
-	// 2. Check token in config file
-	if token := config.GetToken(); token != "" {
-		return token, nil
-	}
-
-	// 3. Interactive prompt
-	return promptForToken()
+// defaultUploadURL derives the GitHub Enterprise Server upload URL from its
+// API URL: https://host/api/v3 uploads to https://host/api/uploads. A bare
+// host URL is left for WithEnterpriseURLs to complete.
+func defaultUploadURL(apiURL string) string {
+	return strings.Replace(apiURL, "/api/v3", "/api/uploads", 1)
 }
 
-func promptForToken() (string, error) {
-	fmt.Print("Enter GitHub token: ")
-	reader := bufio.NewReader(os.Stdin)
-	token, err := reader.ReadString('\n')
-	if err != nil {
-		return "", fmt.Errorf("failed to read token: %w", err)
-	}
-
-	token = strings.TrimSpace(token)
-	if token == "" {
-		return "", fmt.Errorf("token cannot be empty")
-	}
-
-	return token, nil
+// getToken retrieves the GitHub token using the auth flow
+func getToken() (string, error) {
+	return forge.ResolveToken("GITHUB_TOKEN", "GitHub")
 }
 
 // GetToken returns the token used by this client
@@ -80,10 +74,7 @@ func (c *Client) GetToken() string {
 }
 
 // Tag represents a GitHub tag
-type Tag struct {
-	Name   string
-	SHA    string
-}
+type Tag = forge.Tag
 
 // ListTags fetches all tags from a GitHub repository
 func (c *Client) ListTags(ctx context.Context, owner, repo string) ([]Tag, error) {
@@ -112,14 +103,15 @@ func (c *Client) ListTags(ctx context.Context, owner, repo string) ([]Tag, error
 	return allTags, nil
 }
 
-// ParseRepoURL extracts owner and repo from a GitHub URL
+// ParseRepoURL extracts owner and repo from a GitHub or GitHub Enterprise
+// Server URL, in HTTPS, SSH or scp-like form
 func ParseRepoURL(url string) (owner, repo string, err error) {
-	url = strings.TrimSuffix(url, ".git")
-	url = strings.TrimPrefix(url, "https://github.com/")
-	url = strings.TrimPrefix(url, "http://github.com/")
-	url = strings.TrimPrefix(url, "git@github.com:")
+	_, path, err := forge.ParseRepoURL(url)
+	if err != nil {
+		return "", "", err
+	}
 
-	parts := strings.Split(url, "/")
+	parts := strings.Split(path, "/")
 	if len(parts) != 2 {
 		return "", "", fmt.Errorf("invalid GitHub URL format")
 	}
@@ -128,19 +120,16 @@ func ParseRepoURL(url string) (owner, repo string, err error) {
 }
 
 // PullRequest represents a created PR
-type PullRequest struct {
-	Number  int
-	URL     string
-	Title   string
-}
+type PullRequest = forge.PullRequest
 
 // CreatePR creates a pull request from head branch to base branch
-func (c *Client) CreatePR(ctx context.Context, owner, repo, title, body, head, base string) (*PullRequest, error) {
+func (c *Client) CreatePR(ctx context.Context, owner, repo, title, body, head, base string, draft bool) (*PullRequest, error) {
 	pr := &github.NewPullRequest{
 		Title: github.Ptr(title),
 		Body:  github.Ptr(body),
 		Head:  github.Ptr(head),
 		Base:  github.Ptr(base),
+		Draft: github.Ptr(draft),
 	}
 
 	created, _, err := c.PullRequests.Create(ctx, owner, repo, pr)
@@ -148,11 +137,183 @@ func (c *Client) CreatePR(ctx context.Context, owner, repo, title, body, head, b
 		return nil, fmt.Errorf("failed to create pull request: %w", err)
 	}
 
-	return &PullRequest{
-		Number: created.GetNumber(),
-		URL:    created.GetHTMLURL(),
-		Title:  created.GetTitle(),
-	}, nil
+	result := toPullRequest(created)
+	return &result, nil
+}
+
+// toPullRequest converts a GitHub pull request. Open pull requests carry
+// the SHA of a test merge, which is not kept.
+func toPullRequest(pr *github.PullRequest) PullRequest {
+	result := PullRequest{
+		Number: pr.GetNumber(),
+		URL:    pr.GetHTMLURL(),
+		Title:  pr.GetTitle(),
+		Body:   pr.GetBody(),
+		Head:   pr.GetHead().GetRef(),
+		Base:   pr.GetBase().GetRef(),
+		Merged: pr.GetMerged() || pr.MergedAt != nil,
+	}
+	for _, l := range pr.Labels {
+		result.Labels = append(result.Labels, l.GetName())
+	}
+	if result.Merged {
+		result.MergeCommitSHA = pr.GetMergeCommitSHA()
+	}
+	return result
+}
+
+// CommitPRs lists the pull requests that contain a commit
+func (c *Client) CommitPRs(ctx context.Context, owner, repo, sha string) ([]PullRequest, error) {
+	prs, _, err := c.PullRequests.ListPullRequestsWithCommit(ctx, owner, repo, sha, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests of commit %s: %w", sha, err)
+	}
+
+	result := make([]PullRequest, len(prs))
+	for i, pr := range prs {
+		result[i] = toPullRequest(pr)
+	}
+	return result, nil
+}
+
+// FindPR returns the open pull request from head branch to base branch,
+// or nil if there is none
+func (c *Client) FindPR(ctx context.Context, owner, repo, head, base string) (*PullRequest, error) {
+	opts := &github.PullRequestListOptions{
+		State: "open",
+		Head:  owner + ":" + head,
+		Base:  base,
+	}
+	prs, _, err := c.PullRequests.List(ctx, owner, repo, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+	if len(prs) == 0 {
+		return nil, nil
+	}
+
+	pr := toPullRequest(prs[0])
+	return &pr, nil
+}
+
+// ListPRs lists up to 100 pull requests carrying a label, most recently
+// updated first. State is forge.PRStateOpen or forge.PRStateMerged.
+func (c *Client) ListPRs(ctx context.Context, owner, repo, label, state string) ([]PullRequest, error) {
+	issueState := "open"
+	if state == forge.PRStateMerged {
+		issueState = "closed"
+	}
+
+	// The issues API filters by label; pull requests are issues there
+	opts := &github.IssueListByRepoOptions{
+		State:       issueState,
+		Labels:      []string{label},
+		Sort:        "updated",
+		Direction:   "desc",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	issues, _, err := c.Issues.ListByRepo(ctx, owner, repo, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+
+	var prs []PullRequest
+	for _, issue := range issues {
+		if !issue.IsPullRequest() {
+			continue
+		}
+		pr, _, err := c.PullRequests.Get(ctx, owner, repo, issue.GetNumber())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pull request #%d: %w", issue.GetNumber(), err)
+		}
+		if state == forge.PRStateMerged && !pr.GetMerged() {
+			continue // Closed without merging
+		}
+		prs = append(prs, toPullRequest(pr))
+	}
+	return prs, nil
+}
+
+// UpdatePR replaces the title and body of a pull request
+func (c *Client) UpdatePR(ctx context.Context, owner, repo string, number int, title, body string) error {
+	pr := &github.PullRequest{
+		Title: github.Ptr(title),
+		Body:  github.Ptr(body),
+	}
+	if _, _, err := c.PullRequests.Edit(ctx, owner, repo, number, pr); err != nil {
+		return fmt.Errorf("failed to update pull request #%d: %w", number, err)
+	}
+	return nil
+}
+
+// SetPROptions adds labels, reviewers and assignees to a pull request and
+// sets its milestone. GitHub creates labels that do not exist yet.
+// Reviewers of the form org/team are requested as teams.
+func (c *Client) SetPROptions(ctx context.Context, owner, repo string, number int, opts forge.PROptions) error {
+	if len(opts.Labels) > 0 {
+		if _, _, err := c.Issues.AddLabelsToIssue(ctx, owner, repo, number, opts.Labels); err != nil {
+			return fmt.Errorf("failed to label pull request #%d: %w", number, err)
+		}
+	}
+
+	if len(opts.Reviewers) > 0 {
+		var reviewers github.ReviewersRequest
+		for _, r := range opts.Reviewers {
+			if _, team, ok := strings.Cut(r, "/"); ok {
+				reviewers.TeamReviewers = append(reviewers.TeamReviewers, team)
+			} else {
+				reviewers.Reviewers = append(reviewers.Reviewers, r)
+			}
+		}
+		if _, _, err := c.PullRequests.RequestReviewers(ctx, owner, repo, number, reviewers); err != nil {
+			return fmt.Errorf("failed to request reviewers for pull request #%d: %w", number, err)
+		}
+	}
+
+	if len(opts.Assignees) > 0 {
+		if _, _, err := c.Issues.AddAssignees(ctx, owner, repo, number, opts.Assignees); err != nil {
+			return fmt.Errorf("failed to assign pull request #%d: %w", number, err)
+		}
+	}
+
+	if opts.Milestone != "" {
+		milestone, err := c.milestoneNumber(ctx, owner, repo, opts.Milestone)
+		if err != nil {
+			return err
+		}
+		if _, _, err := c.Issues.Edit(ctx, owner, repo, number, &github.IssueRequest{Milestone: github.Ptr(milestone)}); err != nil {
+			return fmt.Errorf("failed to set milestone of pull request #%d: %w", number, err)
+		}
+	}
+
+	return nil
+}
+
+// milestoneNumber returns the number of the open milestone with a title
+func (c *Client) milestoneNumber(ctx context.Context, owner, repo, title string) (int, error) {
+	opts := &github.MilestoneListOptions{
+		State:       "open",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	milestones, _, err := c.Issues.ListMilestones(ctx, owner, repo, opts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list milestones: %w", err)
+	}
+	for _, m := range milestones {
+		if m.GetTitle() == title {
+			return m.GetNumber(), nil
+		}
+	}
+	return 0, fmt.Errorf("milestone %q not found", title)
+}
+
+// CurrentUser returns the login of the user the token belongs to
+func (c *Client) CurrentUser(ctx context.Context) (string, error) {
+	user, _, err := c.Users.Get(ctx, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to get authenticated user: %w", err)
+	}
+	return user.GetLogin(), nil
 }
 
 // GetLatestCommitSHA gets the SHA of the latest commit on a branch
@@ -164,9 +325,27 @@ func (c *Client) GetLatestCommitSHA(ctx context.Context, owner, repo, branch str
 	return ref.GetObject().GetSHA(), nil
 }
 
+// ResolveCommit returns the full SHA of the commit a ref points to
+func (c *Client) ResolveCommit(ctx context.Context, owner, repo, ref string) (string, error) {
+	sha, _, err := c.Repositories.GetCommitSHA1(ctx, owner, repo, ref, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	return sha, nil
+}
+
 // CreateTag creates an annotated tag on a specific commit
 func (c *Client) CreateTag(ctx context.Context, owner, repo, tagName, commitSHA, message string) error {
-	// Create the tag object
+	objectSHA, err := c.CreateTagObject(ctx, owner, repo, tagName, commitSHA, message)
+	if err != nil {
+		return err
+	}
+	return c.CreateTagRef(ctx, owner, repo, tagName, objectSHA)
+}
+
+// CreateTagObject creates an annotated tag object for a commit and returns
+// its SHA. The tag is not visible until CreateTagRef points a ref at it.
+func (c *Client) CreateTagObject(ctx context.Context, owner, repo, tagName, commitSHA, message string) (string, error) {
 	tag := github.CreateTag{
 		Tag:     tagName,
 		Message: message,
@@ -176,19 +355,280 @@ func (c *Client) CreateTag(ctx context.Context, owner, repo, tagName, commitSHA,
 
 	createdTag, _, err := c.Git.CreateTag(ctx, owner, repo, tag)
 	if err != nil {
-		return fmt.Errorf("failed to create tag object: %w", err)
+		return "", fmt.Errorf("failed to create tag object: %w", err)
 	}
+	return createdTag.GetSHA(), nil
+}
 
-	// Create the reference pointing to the tag
+// CreateTagRef creates the reference refs/tags/<tagName> pointing to a tag
+// object
+func (c *Client) CreateTagRef(ctx context.Context, owner, repo, tagName, objectSHA string) error {
 	ref := github.CreateRef{
 		Ref: "refs/tags/" + tagName,
-		SHA: createdTag.GetSHA(),
+		SHA: objectSHA,
 	}
 
-	_, _, err = c.Git.CreateRef(ctx, owner, repo, ref)
-	if err != nil {
+	if _, _, err := c.Git.CreateRef(ctx, owner, repo, ref); err != nil {
 		return fmt.Errorf("failed to create tag reference: %w", err)
 	}
+	return nil
+}
 
+// DeleteTag deletes the reference refs/tags/<tagName>
+func (c *Client) DeleteTag(ctx context.Context, owner, repo, tagName string) error {
+	if _, err := c.Git.DeleteRef(ctx, owner, repo, "refs/tags/"+tagName); err != nil {
+		return fmt.Errorf("failed to delete tag reference: %w", err)
+	}
 	return nil
 }
+
+// Commit represents a commit in a repository's history
+type Commit = forge.Commit
+
+// CompareCommits lists the commits reachable from head but not from base,
+// oldest first
+func (c *Client) CompareCommits(ctx context.Context, owner, repo, base, head string) ([]Commit, error) {
+	var allCommits []Commit
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		comparison, resp, err := c.Repositories.CompareCommits(ctx, owner, repo, base, head, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare commits: %w", err)
+		}
+
+		for _, commit := range comparison.Commits {
+			allCommits = append(allCommits, Commit{
+				SHA:     commit.GetSHA(),
+				Message: commit.GetCommit().GetMessage(),
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return allCommits, nil
+}
+
+// ListCommits lists every commit on a branch, oldest first
+func (c *Client) ListCommits(ctx context.Context, owner, repo, branch string) ([]Commit, error) {
+	var allCommits []Commit
+	opts := &github.CommitsListOptions{
+		SHA:         branch,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	for {
+		commits, resp, err := c.Repositories.ListCommits(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list commits: %w", err)
+		}
+
+		for _, commit := range commits {
+			allCommits = append(allCommits, Commit{
+				SHA:     commit.GetSHA(),
+				Message: commit.GetCommit().GetMessage(),
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	// The API returns newest first
+	forge.Reverse(allCommits)
+
+	return allCommits, nil
+}
+
+// Release represents a published GitHub release
+type Release = forge.Release
+
+// CreateRelease publishes a GitHub release for an existing tag
+func (c *Client) CreateRelease(ctx context.Context, owner, repo, tagName, name, body string, draft, prerelease bool) (*Release, error) {
+	release := &github.RepositoryRelease{
+		TagName:    github.Ptr(tagName),
+		Name:       github.Ptr(name),
+		Body:       github.Ptr(body),
+		Draft:      github.Ptr(draft),
+		Prerelease: github.Ptr(prerelease),
+	}
+
+	created, _, err := c.Repositories.CreateRelease(ctx, owner, repo, release)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create release: %w", err)
+	}
+
+	return &Release{
+		ID:  created.GetID(),
+		URL: created.GetHTMLURL(),
+	}, nil
+}
+
+// CommitFiles lists the paths changed by a commit
+func (c *Client) CommitFiles(ctx context.Context, owner, repo, sha string) ([]string, error) {
+	var files []string
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		commit, resp, err := c.Repositories.GetCommit(ctx, owner, repo, sha, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get commit: %w", err)
+		}
+
+		for _, f := range commit.Files {
+			files = append(files, f.GetFilename())
+			if f.GetPreviousFilename() != "" {
+				files = append(files, f.GetPreviousFilename())
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return files, nil
+}
+
+// GetFile returns the content of a file at a ref
+func (c *Client) GetFile(ctx context.Context, owner, repo, path, ref string) ([]byte, error) {
+	file, _, resp, err := c.Repositories.GetContents(ctx, owner, repo, path, &github.RepositoryContentGetOptions{Ref: ref})
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil, forge.ErrFileNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", path, err)
+	}
+	if file == nil {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return []byte(content), nil
+}
+
+// CreateBranch creates the reference refs/heads/<branch> pointing to a
+// commit
+func (c *Client) CreateBranch(ctx context.Context, owner, repo, branch, sha string) error {
+	ref := github.CreateRef{
+		Ref: "refs/heads/" + branch,
+		SHA: sha,
+	}
+
+	if _, _, err := c.Git.CreateRef(ctx, owner, repo, ref); err != nil {
+		return fmt.Errorf("failed to create branch %s: %w", branch, err)
+	}
+	return nil
+}
+
+// ResetBranch force-moves refs/heads/<branch> to a commit, or creates it
+// if it does not exist
+func (c *Client) ResetBranch(ctx context.Context, owner, repo, branch, sha string) error {
+	_, resp, err := c.Git.UpdateRef(ctx, owner, repo, "refs/heads/"+branch, github.UpdateRef{SHA: sha, Force: github.Ptr(true)})
+	if resp != nil && resp.StatusCode == http.StatusUnprocessableEntity {
+		// GitHub answers 422 for a ref that does not exist
+		return c.CreateBranch(ctx, owner, repo, branch, sha)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to reset branch %s: %w", branch, err)
+	}
+	return nil
+}
+
+// fileMode returns the mode of filePath in the tree treeSHA, or 100644 for
+// a new file. Trees are fetched one directory at a time and cached in trees.
+func (c *Client) fileMode(ctx context.Context, owner, repo, treeSHA, filePath string, trees map[string]*github.Tree) (string, error) {
+	parts := strings.Split(filePath, "/")
+	for i, name := range parts {
+		tree, ok := trees[treeSHA]
+		if !ok {
+			var err error
+			tree, _, err = c.Git.GetTree(ctx, owner, repo, treeSHA, false)
+			if err != nil {
+				return "", fmt.Errorf("failed to get tree %s: %w", treeSHA, err)
+			}
+			trees[treeSHA] = tree
+		}
+
+		var entry *github.TreeEntry
+		for _, e := range tree.Entries {
+			if e.GetPath() == name {
+				entry = e
+				break
+			}
+		}
+		if entry == nil {
+			return "100644", nil
+		}
+		if i == len(parts)-1 {
+			if entry.GetType() != "blob" {
+				return "", fmt.Errorf("%s is a %s, not a file", filePath, entry.GetType())
+			}
+			return entry.GetMode(), nil
+		}
+		if entry.GetType() != "tree" {
+			return "100644", nil
+		}
+		treeSHA = entry.GetSHA()
+	}
+	return "100644", nil
+}
+
+// CommitChanges commits files to a branch with the Git Data API: a tree
+// is created on top of the branch head's tree, then a commit for the tree,
+// then the branch is moved to the commit
+func (c *Client) CommitChanges(ctx context.Context, owner, repo, branch, message string, files []forge.FileChange) (string, error) {
+	head, err := c.GetLatestCommitSHA(ctx, owner, repo, branch)
+	if err != nil {
+		return "", err
+	}
+	parent, _, err := c.Git.GetCommit(ctx, owner, repo, head)
+	if err != nil {
+		return "", fmt.Errorf("failed to get commit %s: %w", head, err)
+	}
+
+	// Existing files keep their mode, e.g. executable scripts
+	trees := make(map[string]*github.Tree)
+	entries := make([]*github.TreeEntry, len(files))
+	for i, f := range files {
+		mode, err := c.fileMode(ctx, owner, repo, parent.GetTree().GetSHA(), f.Path, trees)
+		if err != nil {
+			return "", err
+		}
+		entries[i] = &github.TreeEntry{
+			Path:    github.Ptr(f.Path),
+			Mode:    github.Ptr(mode),
+			Type:    github.Ptr("blob"),
+			Content: github.Ptr(string(f.Content)),
+		}
+	}
+	tree, _, err := c.Git.CreateTree(ctx, owner, repo, parent.GetTree().GetSHA(), entries)
+	if err != nil {
+		return "", fmt.Errorf("failed to create tree: %w", err)
+	}
+
+	commit := github.Commit{
+		Message: github.Ptr(message),
+		Tree:    tree,
+		Parents: []*github.Commit{{SHA: github.Ptr(head)}},
+	}
+	created, _, err := c.Git.CreateCommit(ctx, owner, repo, commit, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create commit: %w", err)
+	}
+
+	if _, _, err := c.Git.UpdateRef(ctx, owner, repo, "refs/heads/"+branch, github.UpdateRef{SHA: created.GetSHA()}); err != nil {
+		return "", fmt.Errorf("failed to update branch %s: %w", branch, err)
+	}
+	return created.GetSHA(), nil
+}
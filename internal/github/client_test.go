@@ -0,0 +1,90 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/v80/github"
+
+	"github.com/excircle/quik-version/internal/forge"
+)
+
+func TestCommitChangesKeepsModes(t *testing.T) {
+	var created []*github.TreeEntry
+	mux := http.NewServeMux()
+	reply := func(w http.ResponseWriter, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+	}
+	mux.HandleFunc("GET /repos/acme/app/git/ref/heads/main", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, map[string]any{"ref": "refs/heads/main", "object": map[string]string{"sha": "head"}})
+	})
+	mux.HandleFunc("GET /repos/acme/app/git/commits/head", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, map[string]any{"sha": "head", "tree": map[string]string{"sha": "root"}})
+	})
+	mux.HandleFunc("GET /repos/acme/app/git/trees/root", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, map[string]any{"sha": "root", "tree": []map[string]string{
+			{"path": "VERSION", "mode": "100644", "type": "blob", "sha": "v"},
+			{"path": "bin", "mode": "040000", "type": "tree", "sha": "bin"},
+		}})
+	})
+	mux.HandleFunc("GET /repos/acme/app/git/trees/bin", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, map[string]any{"sha": "bin", "tree": []map[string]string{
+			{"path": "release.sh", "mode": "100755", "type": "blob", "sha": "r"},
+		}})
+	})
+	mux.HandleFunc("POST /repos/acme/app/git/trees", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Tree []*github.TreeEntry `json:"tree"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode tree: %v", err)
+		}
+		created = body.Tree
+		reply(w, map[string]string{"sha": "tree2"})
+	})
+	mux.HandleFunc("POST /repos/acme/app/git/commits", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, map[string]string{"sha": "bumped"})
+	})
+	mux.HandleFunc("PATCH /repos/acme/app/git/refs/heads/main", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, map[string]any{"ref": "refs/heads/main", "object": map[string]string{"sha": "bumped"}})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := github.NewClient(server.Client())
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	c := &Client{Client: client}
+
+	sha, err := c.CommitChanges(context.Background(), "acme", "app", "main", "chore(release): v1.1.0", []forge.FileChange{
+		{Path: "VERSION", Content: []byte("1.1.0\n")},
+		{Path: "bin/release.sh", Content: []byte("#!/bin/sh\n")},
+		{Path: "bin/new.sh", Content: []byte("#!/bin/sh\n")},
+		{Path: "docs/VERSION", Content: []byte("1.1.0\n")},
+	})
+	if err != nil {
+		t.Fatalf("CommitChanges: %v", err)
+	}
+	if sha != "bumped" {
+		t.Errorf("CommitChanges = %q, want bumped", sha)
+	}
+
+	want := map[string]string{
+		"VERSION":        "100644",
+		"bin/release.sh": "100755",
+		"bin/new.sh":     "100644",
+		"docs/VERSION":   "100644",
+	}
+	if len(created) != len(want) {
+		t.Fatalf("created tree entries = %d, want %d", len(created), len(want))
+	}
+	for _, e := range created {
+		if e.GetMode() != want[e.GetPath()] {
+			t.Errorf("mode of %s = %s, want %s", e.GetPath(), e.GetMode(), want[e.GetPath()])
+		}
+	}
+}
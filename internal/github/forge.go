@@ -0,0 +1,150 @@
+package github
+
+import (
+	"context"
+
+	"github.com/excircle/quik-version/internal/forge"
+)
+
+// Repository binds a Client to a single repository and implements forge.Forge
+type Repository struct {
+	client *Client
+	owner  string
+	repo   string
+}
+
+// NewRepository creates a forge.Forge for a GitHub repository URL
+func NewRepository(ctx context.Context, gitURL string) (*Repository, error) {
+	owner, repo, err := ParseRepoURL(gitURL)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Repository{client: client, owner: owner, repo: repo}, nil
+}
+
+// Kind returns the forge kind
+func (r *Repository) Kind() string {
+	return forge.GitHub
+}
+
+// RepoPath returns owner/repo
+func (r *Repository) RepoPath() string {
+	return r.owner + "/" + r.repo
+}
+
+// CurrentUser returns the login of the authenticated user
+func (r *Repository) CurrentUser(ctx context.Context) (string, error) {
+	return r.client.CurrentUser(ctx)
+}
+
+// ListTags fetches all tags from the repository
+func (r *Repository) ListTags(ctx context.Context) ([]forge.Tag, error) {
+	return r.client.ListTags(ctx, r.owner, r.repo)
+}
+
+// GetLatestCommitSHA gets the SHA of the latest commit on a branch
+func (r *Repository) GetLatestCommitSHA(ctx context.Context, branch string) (string, error) {
+	return r.client.GetLatestCommitSHA(ctx, r.owner, r.repo, branch)
+}
+
+// ResolveCommit returns the full SHA of the commit a ref points to
+func (r *Repository) ResolveCommit(ctx context.Context, ref string) (string, error) {
+	return r.client.ResolveCommit(ctx, r.owner, r.repo, ref)
+}
+
+// CreateTag creates an annotated tag on a specific commit
+func (r *Repository) CreateTag(ctx context.Context, tagName, commitSHA, message string) error {
+	return r.client.CreateTag(ctx, r.owner, r.repo, tagName, commitSHA, message)
+}
+
+// CreateTagObject creates an annotated tag object and returns its SHA
+func (r *Repository) CreateTagObject(ctx context.Context, tagName, commitSHA, message string) (string, error) {
+	return r.client.CreateTagObject(ctx, r.owner, r.repo, tagName, commitSHA, message)
+}
+
+// CreateTagRef creates refs/tags/<tagName> pointing to a tag object
+func (r *Repository) CreateTagRef(ctx context.Context, tagName, objectSHA string) error {
+	return r.client.CreateTagRef(ctx, r.owner, r.repo, tagName, objectSHA)
+}
+
+// DeleteTag deletes a tag ref from the repository
+func (r *Repository) DeleteTag(ctx context.Context, tagName string) error {
+	return r.client.DeleteTag(ctx, r.owner, r.repo, tagName)
+}
+
+// CreatePR creates a pull request from head branch to base branch
+func (r *Repository) CreatePR(ctx context.Context, title, body, head, base string, draft bool) (*forge.PullRequest, error) {
+	return r.client.CreatePR(ctx, r.owner, r.repo, title, body, head, base, draft)
+}
+
+// CreateRelease publishes a GitHub release for an existing tag
+func (r *Repository) CreateRelease(ctx context.Context, tagName, name, body string, draft, prerelease bool) (*forge.Release, error) {
+	return r.client.CreateRelease(ctx, r.owner, r.repo, tagName, name, body, draft, prerelease)
+}
+
+// CompareCommits lists commits reachable from head but not base, oldest first
+func (r *Repository) CompareCommits(ctx context.Context, base, head string) ([]forge.Commit, error) {
+	return r.client.CompareCommits(ctx, r.owner, r.repo, base, head)
+}
+
+// ListCommits lists every commit reachable from ref, oldest first
+func (r *Repository) ListCommits(ctx context.Context, ref string) ([]forge.Commit, error) {
+	return r.client.ListCommits(ctx, r.owner, r.repo, ref)
+}
+
+// CommitFiles lists the paths changed by a commit
+func (r *Repository) CommitFiles(ctx context.Context, sha string) ([]string, error) {
+	return r.client.CommitFiles(ctx, r.owner, r.repo, sha)
+}
+
+// GetFile returns the content of a file at a ref
+func (r *Repository) GetFile(ctx context.Context, path, ref string) ([]byte, error) {
+	return r.client.GetFile(ctx, r.owner, r.repo, path, ref)
+}
+
+// CreateBranch creates a branch pointing to a commit
+func (r *Repository) CreateBranch(ctx context.Context, branch, sha string) error {
+	return r.client.CreateBranch(ctx, r.owner, r.repo, branch, sha)
+}
+
+// ResetBranch points a branch at a commit, creating it if needed
+func (r *Repository) ResetBranch(ctx context.Context, branch, sha string) error {
+	return r.client.ResetBranch(ctx, r.owner, r.repo, branch, sha)
+}
+
+// CommitChanges commits files to a branch as a single commit
+func (r *Repository) CommitChanges(ctx context.Context, branch, message string, files []forge.FileChange) (string, error) {
+	return r.client.CommitChanges(ctx, r.owner, r.repo, branch, message, files)
+}
+
+// FindPR returns the open pull request from head to base, or nil
+func (r *Repository) FindPR(ctx context.Context, head, base string) (*forge.PullRequest, error) {
+	return r.client.FindPR(ctx, r.owner, r.repo, head, base)
+}
+
+// ListPRs lists the pull requests carrying a label in a state
+func (r *Repository) ListPRs(ctx context.Context, label, state string) ([]forge.PullRequest, error) {
+	return r.client.ListPRs(ctx, r.owner, r.repo, label, state)
+}
+
+// UpdatePR replaces the title and body of a pull request
+func (r *Repository) UpdatePR(ctx context.Context, number int, title, body string) error {
+	return r.client.UpdatePR(ctx, r.owner, r.repo, number, title, body)
+}
+
+// SetPROptions adds labels, reviewers and assignees to a pull request and
+// sets its milestone
+func (r *Repository) SetPROptions(ctx context.Context, number int, opts forge.PROptions) error {
+	return r.client.SetPROptions(ctx, r.owner, r.repo, number, opts)
+}
+
+// CommitPRs lists the pull requests that contain a commit
+func (r *Repository) CommitPRs(ctx context.Context, sha string) ([]forge.PullRequest, error) {
+	return r.client.CommitPRs(ctx, r.owner, r.repo, sha)
+}
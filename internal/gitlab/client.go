@@ -0,0 +1,537 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/excircle/quik-version/internal/forge"
+)
+
+// Client is a GitLab REST API (v4) client bound to a single project and
+// implements forge.Forge
+type Client struct {
+	api     *forge.RESTClient
+	project string // namespace/project path
+}
+
+// NewClient creates a GitLab client for a project URL. The token is taken
+// from GITLAB_TOKEN, the config file, or an interactive prompt.
+func NewClient(ctx context.Context, gitURL string) (*Client, error) {
+	host, project, err := forge.ParseRepoURL(gitURL)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := forge.ResolveToken("GITLAB_TOKEN", "GitLab")
+	if err != nil {
+		return nil, err
+	}
+
+	header := http.Header{}
+	header.Set("PRIVATE-TOKEN", token)
+
+	return &Client{
+		api: &forge.RESTClient{
+			BaseURL: forge.APIBaseURL(gitURL, host, "/api/v4"),
+			Header:  header,
+		},
+		project: project,
+	}, nil
+}
+
+// Kind returns the forge kind
+func (c *Client) Kind() string {
+	return forge.GitLab
+}
+
+// RepoPath returns the namespace/project path
+func (c *Client) RepoPath() string {
+	return c.project
+}
+
+// projectPath returns the API path prefix for the project
+func (c *Client) projectPath() string {
+	return "/projects/" + url.PathEscape(c.project)
+}
+
+// CurrentUser returns the username of the user the token belongs to
+func (c *Client) CurrentUser(ctx context.Context) (string, error) {
+	var user struct {
+		Username string `json:"username"`
+	}
+	if _, err := c.api.Do(ctx, http.MethodGet, "/user", nil, &user); err != nil {
+		return "", fmt.Errorf("failed to get authenticated user: %w", err)
+	}
+	return user.Username, nil
+}
+
+type gitlabCommit struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+}
+
+// ListTags fetches all tags from the project
+func (c *Client) ListTags(ctx context.Context) ([]forge.Tag, error) {
+	var allTags []forge.Tag
+
+	for page := 1; page != 0; {
+		var tags []struct {
+			Name   string       `json:"name"`
+			Commit gitlabCommit `json:"commit"`
+		}
+		path := fmt.Sprintf("%s/repository/tags?per_page=100&page=%d", c.projectPath(), page)
+		resp, err := c.api.Do(ctx, http.MethodGet, path, nil, &tags)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tags: %w", err)
+		}
+
+		for _, tag := range tags {
+			allTags = append(allTags, forge.Tag{Name: tag.Name, SHA: tag.Commit.ID})
+		}
+		page = nextPage(resp)
+	}
+
+	return allTags, nil
+}
+
+// GetLatestCommitSHA gets the SHA of the latest commit on a branch
+func (c *Client) GetLatestCommitSHA(ctx context.Context, branch string) (string, error) {
+	var result struct {
+		Commit gitlabCommit `json:"commit"`
+	}
+	path := fmt.Sprintf("%s/repository/branches/%s", c.projectPath(), url.PathEscape(branch))
+	if _, err := c.api.Do(ctx, http.MethodGet, path, nil, &result); err != nil {
+		return "", fmt.Errorf("failed to get branch ref: %w", err)
+	}
+	return result.Commit.ID, nil
+}
+
+// ResolveCommit returns the full SHA of the commit a ref points to
+func (c *Client) ResolveCommit(ctx context.Context, ref string) (string, error) {
+	var commit gitlabCommit
+	path := fmt.Sprintf("%s/repository/commits/%s", c.projectPath(), url.PathEscape(ref))
+	if _, err := c.api.Do(ctx, http.MethodGet, path, nil, &commit); err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	return commit.ID, nil
+}
+
+// CreateTag creates an annotated tag on a specific commit
+func (c *Client) CreateTag(ctx context.Context, tagName, commitSHA, message string) error {
+	body := map[string]string{
+		"tag_name": tagName,
+		"ref":      commitSHA,
+		"message":  message,
+	}
+	if _, err := c.api.Do(ctx, http.MethodPost, c.projectPath()+"/repository/tags", body, nil); err != nil {
+		return fmt.Errorf("failed to create tag: %w", err)
+	}
+	return nil
+}
+
+// DeleteTag deletes a tag from the project
+func (c *Client) DeleteTag(ctx context.Context, tagName string) error {
+	path := c.projectPath() + "/repository/tags/" + url.PathEscape(tagName)
+	if _, err := c.api.Do(ctx, http.MethodDelete, path, nil, nil); err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+	return nil
+}
+
+// draftPrefix marks a merge request as draft in its title
+const draftPrefix = "Draft: "
+
+// CreatePR creates a merge request from head branch to base branch
+func (c *Client) CreatePR(ctx context.Context, title, body, head, base string, draft bool) (*forge.PullRequest, error) {
+	if draft {
+		title = draftPrefix + title
+	}
+	req := map[string]string{
+		"title":         title,
+		"description":   body,
+		"source_branch": head,
+		"target_branch": base,
+	}
+	var created mergeRequest
+	if _, err := c.api.Do(ctx, http.MethodPost, c.projectPath()+"/merge_requests", req, &created); err != nil {
+		return nil, fmt.Errorf("failed to create merge request: %w", err)
+	}
+
+	pr := created.toPullRequest()
+	return &pr, nil
+}
+
+// mergeRequest is a merge request as returned by the API
+type mergeRequest struct {
+	IID             int      `json:"iid"`
+	WebURL          string   `json:"web_url"`
+	Title           string   `json:"title"`
+	Description     string   `json:"description"`
+	SourceBranch    string   `json:"source_branch"`
+	TargetBranch    string   `json:"target_branch"`
+	SHA             string   `json:"sha"`
+	MergeCommitSHA  string   `json:"merge_commit_sha"`
+	SquashCommitSHA string   `json:"squash_commit_sha"`
+	State           string   `json:"state"`
+	Draft           bool     `json:"draft"`
+	Labels          []string `json:"labels"`
+}
+
+// toPullRequest converts a merge request. Fast-forward merges have no
+// merge commit; the merged commit is the squash commit or the head.
+func (mr mergeRequest) toPullRequest() forge.PullRequest {
+	pr := forge.PullRequest{
+		Number: mr.IID,
+		URL:    mr.WebURL,
+		Title:  mr.Title,
+		Body:   mr.Description,
+		Head:   mr.SourceBranch,
+		Base:   mr.TargetBranch,
+		Labels: mr.Labels,
+		Merged: mr.State == "merged",
+	}
+	if pr.Merged {
+		switch {
+		case mr.MergeCommitSHA != "":
+			pr.MergeCommitSHA = mr.MergeCommitSHA
+		case mr.SquashCommitSHA != "":
+			pr.MergeCommitSHA = mr.SquashCommitSHA
+		default:
+			pr.MergeCommitSHA = mr.SHA
+		}
+	}
+	return pr
+}
+
+// FindPR returns the open merge request from head branch to base branch,
+// or nil if there is none
+func (c *Client) FindPR(ctx context.Context, head, base string) (*forge.PullRequest, error) {
+	var mrs []mergeRequest
+	path := fmt.Sprintf("%s/merge_requests?state=opened&source_branch=%s&target_branch=%s", c.projectPath(), url.QueryEscape(head), url.QueryEscape(base))
+	if _, err := c.api.Do(ctx, http.MethodGet, path, nil, &mrs); err != nil {
+		return nil, fmt.Errorf("failed to list merge requests: %w", err)
+	}
+	if len(mrs) == 0 {
+		return nil, nil
+	}
+
+	pr := mrs[0].toPullRequest()
+	return &pr, nil
+}
+
+// CommitPRs lists the merge requests that contain a commit
+func (c *Client) CommitPRs(ctx context.Context, sha string) ([]forge.PullRequest, error) {
+	var mrs []mergeRequest
+	path := fmt.Sprintf("%s/repository/commits/%s/merge_requests", c.projectPath(), url.PathEscape(sha))
+	if _, err := c.api.Do(ctx, http.MethodGet, path, nil, &mrs); err != nil {
+		return nil, fmt.Errorf("failed to list merge requests of commit %s: %w", sha, err)
+	}
+
+	prs := make([]forge.PullRequest, len(mrs))
+	for i, mr := range mrs {
+		prs[i] = mr.toPullRequest()
+	}
+	return prs, nil
+}
+
+// ListPRs lists up to 100 merge requests carrying a label, most recently
+// updated first. State is forge.PRStateOpen or forge.PRStateMerged.
+func (c *Client) ListPRs(ctx context.Context, label, state string) ([]forge.PullRequest, error) {
+	mrState := "opened"
+	if state == forge.PRStateMerged {
+		mrState = "merged"
+	}
+
+	var mrs []mergeRequest
+	path := fmt.Sprintf("%s/merge_requests?state=%s&labels=%s&order_by=updated_at&sort=desc&per_page=100", c.projectPath(), mrState, url.QueryEscape(label))
+	if _, err := c.api.Do(ctx, http.MethodGet, path, nil, &mrs); err != nil {
+		return nil, fmt.Errorf("failed to list merge requests: %w", err)
+	}
+
+	prs := make([]forge.PullRequest, len(mrs))
+	for i, mr := range mrs {
+		prs[i] = mr.toPullRequest()
+	}
+	return prs, nil
+}
+
+// UpdatePR replaces the title and description of a merge request. A
+// draft keeps its draft prefix.
+func (c *Client) UpdatePR(ctx context.Context, number int, title, body string) error {
+	path := fmt.Sprintf("%s/merge_requests/%d", c.projectPath(), number)
+
+	var current mergeRequest
+	if _, err := c.api.Do(ctx, http.MethodGet, path, nil, &current); err != nil {
+		return fmt.Errorf("failed to get merge request !%d: %w", number, err)
+	}
+	if current.Draft {
+		title = draftPrefix + title
+	}
+
+	req := map[string]string{
+		"title":       title,
+		"description": body,
+	}
+	if _, err := c.api.Do(ctx, http.MethodPut, path, req, nil); err != nil {
+		return fmt.Errorf("failed to update merge request !%d: %w", number, err)
+	}
+	return nil
+}
+
+// SetPROptions adds labels to a merge request, sets its reviewers and
+// assignees and its milestone. GitLab creates labels that do not exist
+// yet.
+func (c *Client) SetPROptions(ctx context.Context, number int, opts forge.PROptions) error {
+	req := map[string]any{}
+	if len(opts.Labels) > 0 {
+		req["add_labels"] = strings.Join(opts.Labels, ",")
+	}
+	if len(opts.Reviewers) > 0 {
+		ids, err := c.userIDs(ctx, opts.Reviewers)
+		if err != nil {
+			return err
+		}
+		req["reviewer_ids"] = ids
+	}
+	if len(opts.Assignees) > 0 {
+		ids, err := c.userIDs(ctx, opts.Assignees)
+		if err != nil {
+			return err
+		}
+		req["assignee_ids"] = ids
+	}
+	if opts.Milestone != "" {
+		id, err := c.milestoneID(ctx, opts.Milestone)
+		if err != nil {
+			return err
+		}
+		req["milestone_id"] = id
+	}
+	if len(req) == 0 {
+		return nil
+	}
+
+	path := fmt.Sprintf("%s/merge_requests/%d", c.projectPath(), number)
+	if _, err := c.api.Do(ctx, http.MethodPut, path, req, nil); err != nil {
+		return fmt.Errorf("failed to update merge request !%d: %w", number, err)
+	}
+	return nil
+}
+
+// userIDs returns the IDs of users by username
+func (c *Client) userIDs(ctx context.Context, usernames []string) ([]int, error) {
+	ids := make([]int, len(usernames))
+	for i, username := range usernames {
+		var users []struct {
+			ID int `json:"id"`
+		}
+		if _, err := c.api.Do(ctx, http.MethodGet, "/users?username="+url.QueryEscape(username), nil, &users); err != nil {
+			return nil, fmt.Errorf("failed to look up user %s: %w", username, err)
+		}
+		if len(users) == 0 {
+			return nil, fmt.Errorf("user %s not found", username)
+		}
+		ids[i] = users[0].ID
+	}
+	return ids, nil
+}
+
+// milestoneID returns the ID of the active project milestone with a title
+func (c *Client) milestoneID(ctx context.Context, title string) (int, error) {
+	var milestones []struct {
+		ID int `json:"id"`
+	}
+	path := fmt.Sprintf("%s/milestones?state=active&title=%s", c.projectPath(), url.QueryEscape(title))
+	if _, err := c.api.Do(ctx, http.MethodGet, path, nil, &milestones); err != nil {
+		return 0, fmt.Errorf("failed to list milestones: %w", err)
+	}
+	if len(milestones) == 0 {
+		return 0, fmt.Errorf("milestone %q not found", title)
+	}
+	return milestones[0].ID, nil
+}
+
+// CreateRelease publishes a release for an existing tag. GitLab has no
+// draft or pre-release state, so those options are ignored.
+func (c *Client) CreateRelease(ctx context.Context, tagName, name, body string, draft, prerelease bool) (*forge.Release, error) {
+	req := map[string]string{
+		"tag_name":    tagName,
+		"name":        name,
+		"description": body,
+	}
+	var created struct {
+		Links struct {
+			Self string `json:"self"`
+		} `json:"_links"`
+	}
+	if _, err := c.api.Do(ctx, http.MethodPost, c.projectPath()+"/releases", req, &created); err != nil {
+		return nil, fmt.Errorf("failed to create release: %w", err)
+	}
+
+	return &forge.Release{URL: created.Links.Self}, nil
+}
+
+// CompareCommits lists commits reachable from head but not base, oldest first
+func (c *Client) CompareCommits(ctx context.Context, base, head string) ([]forge.Commit, error) {
+	var result struct {
+		Commits []gitlabCommit `json:"commits"`
+	}
+	path := fmt.Sprintf("%s/repository/compare?from=%s&to=%s", c.projectPath(), url.QueryEscape(base), url.QueryEscape(head))
+	if _, err := c.api.Do(ctx, http.MethodGet, path, nil, &result); err != nil {
+		return nil, fmt.Errorf("failed to compare commits: %w", err)
+	}
+
+	commits := make([]forge.Commit, len(result.Commits))
+	for i, commit := range result.Commits {
+		commits[i] = forge.Commit{SHA: commit.ID, Message: commit.Message}
+	}
+	return commits, nil
+}
+
+// ListCommits lists every commit reachable from ref, oldest first
+func (c *Client) ListCommits(ctx context.Context, ref string) ([]forge.Commit, error) {
+	var allCommits []forge.Commit
+
+	for page := 1; page != 0; {
+		var commits []gitlabCommit
+		path := fmt.Sprintf("%s/repository/commits?ref_name=%s&per_page=100&page=%d", c.projectPath(), url.QueryEscape(ref), page)
+		resp, err := c.api.Do(ctx, http.MethodGet, path, nil, &commits)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list commits: %w", err)
+		}
+
+		for _, commit := range commits {
+			allCommits = append(allCommits, forge.Commit{SHA: commit.ID, Message: commit.Message})
+		}
+		page = nextPage(resp)
+	}
+
+	// The API returns newest first
+	forge.Reverse(allCommits)
+	return allCommits, nil
+}
+
+// CommitFiles lists the paths changed by a commit
+func (c *Client) CommitFiles(ctx context.Context, sha string) ([]string, error) {
+	var files []string
+
+	for page := 1; page != 0; {
+		var diffs []struct {
+			OldPath string `json:"old_path"`
+			NewPath string `json:"new_path"`
+		}
+		path := fmt.Sprintf("%s/repository/commits/%s/diff?per_page=100&page=%d", c.projectPath(), url.PathEscape(sha), page)
+		resp, err := c.api.Do(ctx, http.MethodGet, path, nil, &diffs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get commit diff: %w", err)
+		}
+
+		for _, d := range diffs {
+			files = append(files, d.NewPath)
+			if d.OldPath != d.NewPath {
+				files = append(files, d.OldPath)
+			}
+		}
+		page = nextPage(resp)
+	}
+
+	return files, nil
+}
+
+// nextPage returns the next page number from GitLab's pagination headers,
+// or 0 on the last page
+func nextPage(resp *http.Response) int {
+	next, err := strconv.Atoi(resp.Header.Get("X-Next-Page"))
+	if err != nil {
+		return 0
+	}
+	return next
+}
+
+// GetFile returns the content of a file at a ref
+func (c *Client) GetFile(ctx context.Context, filePath, ref string) ([]byte, error) {
+	var file struct {
+		Content string `json:"content"`
+	}
+	path := fmt.Sprintf("%s/repository/files/%s?ref=%s", c.projectPath(), url.PathEscape(filePath), url.QueryEscape(ref))
+	resp, err := c.api.Do(ctx, http.MethodGet, path, nil, &file)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil, forge.ErrFileNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", filePath, err)
+	}
+
+	content, err := base64.StdEncoding.DecodeString(file.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", filePath, err)
+	}
+	return content, nil
+}
+
+// CreateBranch creates a branch pointing to a commit
+func (c *Client) CreateBranch(ctx context.Context, branch, sha string) error {
+	body := map[string]string{
+		"branch": branch,
+		"ref":    sha,
+	}
+	if _, err := c.api.Do(ctx, http.MethodPost, c.projectPath()+"/repository/branches", body, nil); err != nil {
+		return fmt.Errorf("failed to create branch %s: %w", branch, err)
+	}
+	return nil
+}
+
+// ResetBranch points a branch at a commit by deleting and recreating it.
+// Open merge requests from the branch stay open.
+func (c *Client) ResetBranch(ctx context.Context, branch, sha string) error {
+	path := fmt.Sprintf("%s/repository/branches/%s", c.projectPath(), url.PathEscape(branch))
+	resp, err := c.api.Do(ctx, http.MethodDelete, path, nil, nil)
+	if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+		return fmt.Errorf("failed to reset branch %s: %w", branch, err)
+	}
+	return c.CreateBranch(ctx, branch, sha)
+}
+
+// CommitChanges commits files to a branch as a single commit with the
+// commits API. Files that do not exist on the branch yet are created.
+func (c *Client) CommitChanges(ctx context.Context, branch, message string, files []forge.FileChange) (string, error) {
+	type commitAction struct {
+		Action   string `json:"action"`
+		FilePath string `json:"file_path"`
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+
+	actions := make([]commitAction, len(files))
+	for i, f := range files {
+		action := "update"
+		if _, err := c.GetFile(ctx, f.Path, branch); errors.Is(err, forge.ErrFileNotFound) {
+			action = "create"
+		} else if err != nil {
+			return "", err
+		}
+		actions[i] = commitAction{
+			Action:   action,
+			FilePath: f.Path,
+			Content:  base64.StdEncoding.EncodeToString(f.Content),
+			Encoding: "base64",
+		}
+	}
+
+	body := map[string]any{
+		"branch":         branch,
+		"commit_message": message,
+		"actions":        actions,
+	}
+	var created gitlabCommit
+	if _, err := c.api.Do(ctx, http.MethodPost, c.projectPath()+"/repository/commits", body, &created); err != nil {
+		return "", fmt.Errorf("failed to create commit: %w", err)
+	}
+	return created.ID, nil
+}
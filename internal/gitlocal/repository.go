@@ -0,0 +1,287 @@
+package gitlocal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/excircle/quik-version/internal/forge"
+)
+
+// headsNamespace is where remote branches are fetched to, so any remote
+// (a configured name or a URL) can be used without touching the user's
+// remote-tracking branches
+const headsNamespace = "refs/qv/heads/"
+
+// Repository drives the git CLI in a local clone and implements forge.Forge
+// without any forge API. Tags are created locally and pushed to the remote.
+type Repository struct {
+	dir    string
+	remote string
+}
+
+// NewRepository creates a local-git backend for the clone in dir that
+// fetches from and pushes to remote (a remote name or URL)
+func NewRepository(dir, remote string) (*Repository, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("git not found in PATH")
+	}
+
+	r := &Repository{dir: dir, remote: remote}
+	if _, err := r.git(context.Background(), "rev-parse", "--git-dir"); err != nil {
+		return nil, fmt.Errorf("%s is not a git repository: %w", dir, err)
+	}
+
+	return r, nil
+}
+
+// Kind returns the forge kind
+func (r *Repository) Kind() string {
+	return forge.Git
+}
+
+// RepoPath returns the remote the repository pushes to
+func (r *Repository) RepoPath() string {
+	return r.remote
+}
+
+// CurrentUser returns the committer identity configured for the clone
+func (r *Repository) CurrentUser(ctx context.Context) (string, error) {
+	if email, err := r.git(ctx, "config", "user.email"); err == nil && email != "" {
+		return email, nil
+	}
+	name, err := r.git(ctx, "config", "user.name")
+	if err != nil || name == "" {
+		return "", fmt.Errorf("git user.name and user.email are not set")
+	}
+	return name, nil
+}
+
+// git runs a git command in the repository and returns its trimmed output
+func (r *Repository) git(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", r.dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// fetch updates tags and branch heads from the remote
+func (r *Repository) fetch(ctx context.Context) error {
+	_, err := r.git(ctx, "fetch", "--quiet", "--force", "--tags", r.remote, "+refs/heads/*:"+headsNamespace+"*")
+	if err != nil {
+		return fmt.Errorf("failed to fetch from %s: %w", r.remote, err)
+	}
+	return nil
+}
+
+// resolve returns the commit SHA for a fetched branch name or any other
+// revision (SHA, tag)
+func (r *Repository) resolve(ctx context.Context, ref string) (string, error) {
+	if sha, err := r.git(ctx, "rev-parse", "--verify", "--quiet", headsNamespace+ref+"^{commit}"); err == nil {
+		return sha, nil
+	}
+	sha, err := r.git(ctx, "rev-parse", "--verify", ref+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("unknown revision %s", ref)
+	}
+	return sha, nil
+}
+
+// ListTags lists the tags on the remote with the commit each one points
+// to
+func (r *Repository) ListTags(ctx context.Context) ([]forge.Tag, error) {
+	// Only the remote is listed: local clones may hold tags that were never
+	// pushed or were deleted on the remote since the last fetch
+	out, err := r.git(ctx, "ls-remote", "--tags", r.remote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	// Annotated tags are listed twice; the peeled "^{}" entry is the commit
+	var names []string
+	shas := make(map[string]string)
+	peeled := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || !strings.HasPrefix(fields[1], "refs/tags/") {
+			continue
+		}
+		name := strings.TrimPrefix(fields[1], "refs/tags/")
+		if tag, ok := strings.CutSuffix(name, "^{}"); ok {
+			peeled[tag] = fields[0]
+			continue
+		}
+		names = append(names, name)
+		shas[name] = fields[0]
+	}
+
+	var tags []forge.Tag
+	for _, name := range names {
+		sha := shas[name]
+		if commit, ok := peeled[name]; ok {
+			sha = commit
+		}
+		tags = append(tags, forge.Tag{Name: name, SHA: sha})
+	}
+	return tags, nil
+}
+
+// GetLatestCommitSHA fetches the remote and returns the head of branch
+func (r *Repository) GetLatestCommitSHA(ctx context.Context, branch string) (string, error) {
+	if err := r.fetch(ctx); err != nil {
+		return "", err
+	}
+
+	sha, err := r.git(ctx, "rev-parse", "--verify", headsNamespace+branch+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("failed to get branch ref: branch %s not found on %s", branch, r.remote)
+	}
+	return sha, nil
+}
+
+// ResolveCommit fetches the remote and returns the full SHA of the commit
+// a ref points to
+func (r *Repository) ResolveCommit(ctx context.Context, ref string) (string, error) {
+	if err := r.fetch(ctx); err != nil {
+		return "", err
+	}
+
+	sha, err := r.resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	return sha, nil
+}
+
+// CreateTag creates an annotated tag locally and pushes it to the remote
+func (r *Repository) CreateTag(ctx context.Context, tagName, commitSHA, message string) error {
+	objectSHA, err := r.CreateTagObject(ctx, tagName, commitSHA, message)
+	if err != nil {
+		return err
+	}
+	return r.CreateTagRef(ctx, tagName, objectSHA)
+}
+
+// CreateTagObject creates an annotated tag in the local clone and returns
+// the tag object's SHA
+func (r *Repository) CreateTagObject(ctx context.Context, tagName, commitSHA, message string) (string, error) {
+	if _, err := r.git(ctx, "tag", "--annotate", "--message", message, tagName, commitSHA); err != nil {
+		return "", fmt.Errorf("failed to create tag object: %w", err)
+	}
+
+	sha, err := r.git(ctx, "rev-parse", "refs/tags/"+tagName)
+	if err != nil {
+		return "", fmt.Errorf("failed to create tag object: %w", err)
+	}
+	return sha, nil
+}
+
+// CreateTagRef pushes the tag object to refs/tags/<tagName> on the remote
+func (r *Repository) CreateTagRef(ctx context.Context, tagName, objectSHA string) error {
+	if _, err := r.git(ctx, "push", "--quiet", r.remote, objectSHA+":refs/tags/"+tagName); err != nil {
+		return fmt.Errorf("failed to push tag: %w", err)
+	}
+	return nil
+}
+
+// DeleteTag deletes the tag from the remote and the local clone. A tag
+// that was created locally but never pushed is only deleted locally.
+func (r *Repository) DeleteTag(ctx context.Context, tagName string) error {
+	remoteRef, err := r.git(ctx, "ls-remote", "--tags", r.remote, "refs/tags/"+tagName)
+	if err != nil {
+		return fmt.Errorf("failed to list remote tags: %w", err)
+	}
+	if remoteRef != "" {
+		if _, err := r.git(ctx, "push", "--quiet", r.remote, ":refs/tags/"+tagName); err != nil {
+			return fmt.Errorf("failed to delete remote tag: %w", err)
+		}
+	}
+
+	// The local tag may not exist if the deploy ran in another clone
+	if _, err := r.git(ctx, "rev-parse", "--verify", "--quiet", "refs/tags/"+tagName); err == nil {
+		if _, err := r.git(ctx, "tag", "--delete", tagName); err != nil {
+			return fmt.Errorf("failed to delete local tag: %w", err)
+		}
+	}
+	return nil
+}
+
+// CreatePR is not supported without a forge API
+func (r *Repository) CreatePR(ctx context.Context, title, body, head, base string, draft bool) (*forge.PullRequest, error) {
+	return nil, fmt.Errorf("pull requests are not supported by the local git backend")
+}
+
+// CreateRelease is not supported without a forge API
+func (r *Repository) CreateRelease(ctx context.Context, tagName, name, body string, draft, prerelease bool) (*forge.Release, error) {
+	return nil, fmt.Errorf("releases are not supported by the local git backend")
+}
+
+// CompareCommits lists commits reachable from head but not base, oldest first
+func (r *Repository) CompareCommits(ctx context.Context, base, head string) ([]forge.Commit, error) {
+	if err := r.fetch(ctx); err != nil {
+		return nil, err
+	}
+
+	baseSHA, err := r.resolve(ctx, base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare commits: %w", err)
+	}
+	headSHA, err := r.resolve(ctx, head)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare commits: %w", err)
+	}
+
+	return r.log(ctx, baseSHA+".."+headSHA)
+}
+
+// ListCommits lists every commit reachable from ref, oldest first
+func (r *Repository) ListCommits(ctx context.Context, ref string) ([]forge.Commit, error) {
+	if err := r.fetch(ctx); err != nil {
+		return nil, err
+	}
+
+	sha, err := r.resolve(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits: %w", err)
+	}
+
+	return r.log(ctx, sha)
+}
+
+// CommitFiles lists the paths changed by a commit
+func (r *Repository) CommitFiles(ctx context.Context, sha string) ([]string, error) {
+	out, err := r.git(ctx, "diff-tree", "--no-commit-id", "--name-only", "-r", "--root", sha)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit files: %w", err)
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// log lists commits in a revision range, oldest first
+func (r *Repository) log(ctx context.Context, revRange string) ([]forge.Commit, error) {
+	// Separate SHA and message with NUL and records with RS
+	out, err := r.git(ctx, "log", "--reverse", "--format=%H%x00%B%x1e", revRange)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits: %w", err)
+	}
+
+	var commits []forge.Commit
+	for _, record := range strings.Split(out, "\x1e") {
+		sha, message, ok := strings.Cut(strings.TrimSpace(record), "\x00")
+		if !ok {
+			continue
+		}
+		commits = append(commits, forge.Commit{SHA: sha, Message: strings.TrimSpace(message)})
+	}
+	return commits, nil
+}
@@ -0,0 +1,255 @@
+package gitlocal
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testRepo is a clone with a bare remote and a few commits on main
+type testRepo struct {
+	repo    *Repository
+	dir     string
+	remote  string
+	commits []string // SHAs, oldest first
+}
+
+// run runs git in dir and returns its trimmed output
+func run(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// newTestRepo creates a bare remote and a clone with one commit per entry
+// of files (path -> content), pushed to main
+func newTestRepo(t *testing.T, files ...map[string]string) *testRepo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found in PATH")
+	}
+
+	root := t.TempDir()
+	remote := filepath.Join(root, "remote.git")
+	dir := filepath.Join(root, "clone")
+	run(t, root, "init", "--quiet", "--bare", "--initial-branch=main", remote)
+	run(t, root, "init", "--quiet", "--initial-branch=main", dir)
+	run(t, dir, "config", "user.name", "Test")
+	run(t, dir, "config", "user.email", "test@example.com")
+	run(t, dir, "config", "commit.gpgsign", "false")
+	run(t, dir, "config", "tag.gpgsign", "false")
+
+	tr := &testRepo{dir: dir, remote: remote}
+	for i, change := range files {
+		for path, content := range change {
+			full := filepath.Join(dir, path)
+			if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		run(t, dir, "add", "--all")
+		run(t, dir, "commit", "--quiet", "--message", "commit "+string(rune('a'+i)))
+		tr.commits = append(tr.commits, run(t, dir, "rev-parse", "HEAD"))
+	}
+	run(t, dir, "push", "--quiet", remote, "main")
+
+	repo, err := NewRepository(dir, remote)
+	if err != nil {
+		t.Fatalf("NewRepository: %v", err)
+	}
+	tr.repo = repo
+	return tr
+}
+
+func TestNewRepositoryNotGit(t *testing.T) {
+	if _, err := NewRepository(t.TempDir(), "origin"); err == nil {
+		t.Fatal("NewRepository on a plain directory succeeded, want error")
+	}
+}
+
+func TestCreateAndListTags(t *testing.T) {
+	ctx := context.Background()
+	tr := newTestRepo(t, map[string]string{"a.txt": "a"}, map[string]string{"b.txt": "b"})
+
+	tags, err := tr.repo.ListTags(ctx)
+	if err != nil {
+		t.Fatalf("ListTags: %v", err)
+	}
+	if len(tags) != 0 {
+		t.Fatalf("ListTags = %v, want none", tags)
+	}
+
+	if err := tr.repo.CreateTag(ctx, "v1.0.0", tr.commits[0], "Release v1.0.0"); err != nil {
+		t.Fatalf("CreateTag: %v", err)
+	}
+	if err := tr.repo.CreateTag(ctx, "v1.1.0", tr.commits[1], "Release v1.1.0"); err != nil {
+		t.Fatalf("CreateTag: %v", err)
+	}
+
+	// The tags are pushed to the remote
+	if got := run(t, tr.remote, "rev-parse", "v1.0.0^{commit}"); got != tr.commits[0] {
+		t.Errorf("remote v1.0.0 points to %s, want %s", got, tr.commits[0])
+	}
+
+	// A lightweight tag pushed by someone else is listed too
+	run(t, tr.remote, "tag", "v0.1.0", tr.commits[0])
+
+	tags, err = tr.repo.ListTags(ctx)
+	if err != nil {
+		t.Fatalf("ListTags: %v", err)
+	}
+	got := make(map[string]string)
+	for _, tag := range tags {
+		got[tag.Name] = tag.SHA
+	}
+	want := map[string]string{
+		"v0.1.0": tr.commits[0],
+		"v1.0.0": tr.commits[0],
+		"v1.1.0": tr.commits[1],
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ListTags = %v, want %v", got, want)
+	}
+	for name, sha := range want {
+		if got[name] != sha {
+			t.Errorf("tag %s points to %s, want %s (peeled commit)", name, got[name], sha)
+		}
+	}
+
+	// Creating an existing tag fails
+	if err := tr.repo.CreateTag(ctx, "v1.0.0", tr.commits[1], "again"); err == nil {
+		t.Error("CreateTag of an existing tag succeeded, want error")
+	}
+}
+
+func TestListTagsRemoteOnly(t *testing.T) {
+	ctx := context.Background()
+	tr := newTestRepo(t, map[string]string{"a.txt": "a"})
+
+	if err := tr.repo.CreateTag(ctx, "v1.0.0", tr.commits[0], "Release v1.0.0"); err != nil {
+		t.Fatalf("CreateTag: %v", err)
+	}
+	if err := tr.repo.CreateTag(ctx, "v1.1.0", tr.commits[0], "Release v1.1.0"); err != nil {
+		t.Fatalf("CreateTag: %v", err)
+	}
+
+	// A tag that was never pushed and one deleted on the remote are still
+	// in the clone
+	run(t, tr.dir, "tag", "v2.0.0-local", tr.commits[0])
+	run(t, tr.remote, "tag", "--delete", "v1.1.0")
+
+	tags, err := tr.repo.ListTags(ctx)
+	if err != nil {
+		t.Fatalf("ListTags: %v", err)
+	}
+	if len(tags) != 1 || tags[0].Name != "v1.0.0" || tags[0].SHA != tr.commits[0] {
+		t.Errorf("ListTags = %v, want only v1.0.0 at %s", tags, tr.commits[0])
+	}
+}
+
+func TestDeleteTag(t *testing.T) {
+	ctx := context.Background()
+	tr := newTestRepo(t, map[string]string{"a.txt": "a"})
+
+	if err := tr.repo.CreateTag(ctx, "v1.0.0", tr.commits[0], "Release v1.0.0"); err != nil {
+		t.Fatalf("CreateTag: %v", err)
+	}
+	if err := tr.repo.DeleteTag(ctx, "v1.0.0"); err != nil {
+		t.Fatalf("DeleteTag: %v", err)
+	}
+	if out := run(t, tr.remote, "tag", "--list"); out != "" {
+		t.Errorf("remote tags after DeleteTag = %q, want none", out)
+	}
+	if out := run(t, tr.dir, "tag", "--list"); out != "" {
+		t.Errorf("local tags after DeleteTag = %q, want none", out)
+	}
+
+	// A tag that was never pushed is only deleted locally
+	if _, err := tr.repo.CreateTagObject(ctx, "v1.0.1", tr.commits[0], "local only"); err != nil {
+		t.Fatalf("CreateTagObject: %v", err)
+	}
+	if err := tr.repo.DeleteTag(ctx, "v1.0.1"); err != nil {
+		t.Fatalf("DeleteTag of a local tag: %v", err)
+	}
+	if out := run(t, tr.dir, "tag", "--list"); out != "" {
+		t.Errorf("local tags after DeleteTag = %q, want none", out)
+	}
+
+	// Deleting a tag that exists nowhere is not an error
+	if err := tr.repo.DeleteTag(ctx, "v9.9.9"); err != nil {
+		t.Errorf("DeleteTag of a missing tag: %v", err)
+	}
+}
+
+func TestCompareCommits(t *testing.T) {
+	ctx := context.Background()
+	tr := newTestRepo(t,
+		map[string]string{"a.txt": "a"},
+		map[string]string{"b.txt": "b"},
+		map[string]string{"c.txt": "c"},
+	)
+
+	commits, err := tr.repo.CompareCommits(ctx, tr.commits[0], "main")
+	if err != nil {
+		t.Fatalf("CompareCommits: %v", err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("CompareCommits returned %d commits, want 2", len(commits))
+	}
+	for i, c := range commits {
+		if c.SHA != tr.commits[i+1] {
+			t.Errorf("commit %d = %s, want %s (oldest first)", i, c.SHA, tr.commits[i+1])
+		}
+	}
+	if commits[0].Message != "commit b" {
+		t.Errorf("commit message = %q, want %q", commits[0].Message, "commit b")
+	}
+
+	// A base that descends from head has nothing to compare
+	commits, err = tr.repo.CompareCommits(ctx, tr.commits[2], tr.commits[0])
+	if err != nil {
+		t.Fatalf("CompareCommits: %v", err)
+	}
+	if len(commits) != 0 {
+		t.Errorf("CompareCommits(descendant, ancestor) = %v, want none", commits)
+	}
+
+	if _, err := tr.repo.CompareCommits(ctx, "no-such-ref", "main"); err == nil {
+		t.Error("CompareCommits with an unknown base succeeded, want error")
+	}
+}
+
+func TestCommitFiles(t *testing.T) {
+	ctx := context.Background()
+	tr := newTestRepo(t,
+		map[string]string{"a.txt": "a", "api/main.go": "package main"},
+		map[string]string{"api/main.go": "package main\n", "worker/run.sh": "#!/bin/sh"},
+	)
+
+	tests := []struct {
+		sha  string
+		want []string
+	}{
+		{tr.commits[0], []string{"a.txt", "api/main.go"}},
+		{tr.commits[1], []string{"api/main.go", "worker/run.sh"}},
+	}
+	for _, tt := range tests {
+		files, err := tr.repo.CommitFiles(ctx, tt.sha)
+		if err != nil {
+			t.Fatalf("CommitFiles(%s): %v", tt.sha[:7], err)
+		}
+		if strings.Join(files, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("CommitFiles(%s) = %v, want %v", tt.sha[:7], files, tt.want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Package prompt asks the user for input on a terminal. Prompts fail fast
+// instead of waiting for input when qv runs without a terminal (e.g. in CI)
+// or with --non-interactive.
+package prompt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var (
+	// NonInteractive disables every prompt
+	NonInteractive bool
+	// AssumeYes answers yes to every confirmation without asking
+	AssumeYes bool
+)
+
+// ErrNoInput is returned when a prompt needs an answer but qv is not
+// running interactively
+var ErrNoInput = errors.New("input required, but qv is not running interactively")
+
+var reader = bufio.NewReader(os.Stdin)
+
+// Interactive reports whether prompts can be shown: --non-interactive was
+// not given and stdin is a terminal
+func Interactive() bool {
+	if NonInteractive || AssumeYes {
+		return false
+	}
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+
+	// /dev/null is a character device too, but nobody can answer from it
+	if null, err := os.Stat(os.DevNull); err == nil && os.SameFile(info, null) {
+		return false
+	}
+	return true
+}
+
+// String asks for a line of input and returns it trimmed. Prompts are
+// written to stderr so they never mix with machine-readable output.
+func String(label string) (string, error) {
+	if !Interactive() {
+		return "", ErrNoInput
+	}
+
+	fmt.Fprint(os.Stderr, label)
+	line, err := reader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// Confirm asks a yes/no question. With --yes it returns true without
+// asking; without a terminal it returns ErrNoInput.
+func Confirm(question string) (bool, error) {
+	if AssumeYes {
+		return true, nil
+	}
+
+	response, err := String(question + " (y/n): ")
+	if err != nil {
+		return false, err
+	}
+	response = strings.ToLower(response)
+	return response == "y" || response == "yes", nil
+}
@@ -6,31 +6,223 @@ import (
 	"strings"
 )
 
-// Parse parses a version string (with or without 'v' prefix) into major, minor, patch
-func Parse(v string) (major, minor, patch int, err error) {
+// Version represents a parsed Semantic Versioning 2.0.0 version
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	PreRelease []string
+	Build      []string
+}
+
+// Parse parses a version string (with or without 'v' prefix) into a Version.
+// Pre-release identifiers follow a '-' and build metadata follows a '+'.
+func Parse(v string) (*Version, error) {
+	original := v
 	v = strings.TrimPrefix(v, "v")
-	parts := strings.Split(v, ".")
 
+	var parsed Version
+
+	// Split off build metadata first, it may contain '-'
+	if idx := strings.Index(v, "+"); idx >= 0 {
+		build, err := parseIdentifiers(v[idx+1:], false)
+		if err != nil {
+			return nil, fmt.Errorf("invalid build metadata in %s: %w", original, err)
+		}
+		parsed.Build = build
+		v = v[:idx]
+	}
+
+	// Split off pre-release identifiers
+	if idx := strings.Index(v, "-"); idx >= 0 {
+		pre, err := parseIdentifiers(v[idx+1:], true)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pre-release in %s: %w", original, err)
+		}
+		parsed.PreRelease = pre
+		v = v[:idx]
+	}
+
+	parts := strings.Split(v, ".")
 	if len(parts) != 3 {
-		return 0, 0, 0, fmt.Errorf("invalid version format: %s", v)
+		return nil, fmt.Errorf("invalid version format: %s", original)
 	}
 
-	major, err = strconv.Atoi(parts[0])
+	var err error
+	parsed.Major, err = parseNumber(parts[0])
 	if err != nil {
-		return 0, 0, 0, fmt.Errorf("invalid major version: %s", parts[0])
+		return nil, fmt.Errorf("invalid major version: %s", parts[0])
 	}
 
-	minor, err = strconv.Atoi(parts[1])
+	parsed.Minor, err = parseNumber(parts[1])
 	if err != nil {
-		return 0, 0, 0, fmt.Errorf("invalid minor version: %s", parts[1])
+		return nil, fmt.Errorf("invalid minor version: %s", parts[1])
 	}
 
-	patch, err = strconv.Atoi(parts[2])
+	parsed.Patch, err = parseNumber(parts[2])
 	if err != nil {
-		return 0, 0, 0, fmt.Errorf("invalid patch version: %s", parts[2])
+		return nil, fmt.Errorf("invalid patch version: %s", parts[2])
 	}
 
-	return major, minor, patch, nil
+	return &parsed, nil
+}
+
+// IsValid reports whether v is a valid semantic version
+func IsValid(v string) bool {
+	_, err := Parse(v)
+	return err == nil
+}
+
+// parseNumber parses a non-negative integer without leading zeros
+func parseNumber(s string) (int, error) {
+	if s == "" {
+		return 0, fmt.Errorf("empty number")
+	}
+	if len(s) > 1 && s[0] == '0' {
+		return 0, fmt.Errorf("leading zero in %s", s)
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("non-digit in %s", s)
+		}
+	}
+	return strconv.Atoi(s)
+}
+
+// parseIdentifiers splits a dot-separated identifier list and validates each
+// identifier. Numeric pre-release identifiers must not have leading zeros.
+func parseIdentifiers(s string, preRelease bool) ([]string, error) {
+	ids := strings.Split(s, ".")
+	for _, id := range ids {
+		if id == "" {
+			return nil, fmt.Errorf("empty identifier")
+		}
+		for _, r := range id {
+			if !isIdentifierChar(r) {
+				return nil, fmt.Errorf("invalid character %q in identifier %s", r, id)
+			}
+		}
+		if preRelease && isNumeric(id) && len(id) > 1 && id[0] == '0' {
+			return nil, fmt.Errorf("leading zero in numeric identifier %s", id)
+		}
+	}
+	return ids, nil
+}
+
+func isIdentifierChar(r rune) bool {
+	return (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r == '-'
+}
+
+func isNumeric(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// String formats the version (without 'v' prefix)
+func (v *Version) String() string {
+	s := Format(v.Major, v.Minor, v.Patch)
+	if len(v.PreRelease) > 0 {
+		s += "-" + strings.Join(v.PreRelease, ".")
+	}
+	if len(v.Build) > 0 {
+		s += "+" + strings.Join(v.Build, ".")
+	}
+	return s
+}
+
+// Core returns the MAJOR.MINOR.PATCH portion of the version
+func (v *Version) Core() *Version {
+	return &Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+}
+
+// IsPreRelease reports whether the version has pre-release identifiers
+func (v *Version) IsPreRelease() bool {
+	return len(v.PreRelease) > 0
+}
+
+// Compare returns -1, 0 or 1 depending on whether v has lower, equal or
+// higher precedence than other. Build metadata is ignored.
+func (v *Version) Compare(other *Version) int {
+	if c := compareInt(v.Major, other.Major); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Minor, other.Minor); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Patch, other.Patch); c != 0 {
+		return c
+	}
+
+	// A pre-release version has lower precedence than the normal version
+	switch {
+	case len(v.PreRelease) == 0 && len(other.PreRelease) == 0:
+		return 0
+	case len(v.PreRelease) == 0:
+		return 1
+	case len(other.PreRelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.PreRelease) && i < len(other.PreRelease); i++ {
+		if c := compareIdentifier(v.PreRelease[i], other.PreRelease[i]); c != 0 {
+			return c
+		}
+	}
+
+	// A larger set of pre-release fields has higher precedence
+	return compareInt(len(v.PreRelease), len(other.PreRelease))
+}
+
+// LessThan reports whether v has lower precedence than other
+func (v *Version) LessThan(other *Version) bool {
+	return v.Compare(other) < 0
+}
+
+// Compare parses and compares two version strings
+func Compare(a, b string) (int, error) {
+	va, err := Parse(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := Parse(b)
+	if err != nil {
+		return 0, err
+	}
+	return va.Compare(vb), nil
+}
+
+// compareIdentifier compares pre-release identifiers. Numeric identifiers
+// compare numerically and always have lower precedence than alphanumeric ones.
+func compareIdentifier(a, b string) int {
+	aNum, bNum := isNumeric(a), isNumeric(b)
+	switch {
+	case aNum && bNum:
+		if len(a) != len(b) {
+			return compareInt(len(a), len(b))
+		}
+		return strings.Compare(a, b)
+	case aNum:
+		return -1
+	case bNum:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
 }
 
 // Format formats major, minor, patch into a version string (without 'v' prefix)
@@ -38,31 +230,31 @@ func Format(major, minor, patch int) string {
 	return fmt.Sprintf("%d.%d.%d", major, minor, patch)
 }
 
-// IncrementMajor increments the major version and resets minor and patch
-func IncrementMajor(v string) string {
-	major, _, _, err := Parse(v)
-	if err != nil {
-		return "1.0.0"
+// NextMajor returns the next major version. A pre-release of a major
+// version (X.0.0-pre) is released as X.0.0.
+func (v *Version) NextMajor() *Version {
+	if v.IsPreRelease() && v.Minor == 0 && v.Patch == 0 {
+		return v.Core()
 	}
-	return Format(major+1, 0, 0)
+	return &Version{Major: v.Major + 1}
 }
 
-// IncrementMinor increments the minor version and resets patch
-func IncrementMinor(v string) string {
-	major, minor, _, err := Parse(v)
-	if err != nil {
-		return "0.1.0"
+// NextMinor returns the next minor version. A pre-release of a minor
+// version (X.Y.0-pre) is released as X.Y.0.
+func (v *Version) NextMinor() *Version {
+	if v.IsPreRelease() && v.Patch == 0 {
+		return v.Core()
 	}
-	return Format(major, minor+1, 0)
+	return &Version{Major: v.Major, Minor: v.Minor + 1}
 }
 
-// IncrementPatch increments the patch version
-func IncrementPatch(v string) string {
-	major, minor, patch, err := Parse(v)
-	if err != nil {
-		return "0.0.1"
+// NextPatch returns the next patch version. A pre-release is released
+// as its MAJOR.MINOR.PATCH version.
+func (v *Version) NextPatch() *Version {
+	if v.IsPreRelease() {
+		return v.Core()
 	}
-	return Format(major, minor, patch+1)
+	return &Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
 }
 
 // Initial returns the initial version for a given increment type
@@ -76,3 +268,154 @@ func Initial(incrementType string) string {
 		return "0.1.0"
 	}
 }
+
+// Channels lists the supported pre-release channels in precedence order
+var Channels = []string{"alpha", "beta", "rc"}
+
+// IsChannel reports whether channel is a supported pre-release channel
+func IsChannel(channel string) bool {
+	for _, c := range Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// Channel returns the pre-release channel of the version (e.g. "rc" for
+// 1.5.0-rc.2), or an empty string for a normal version
+func (v *Version) Channel() string {
+	if !v.IsPreRelease() {
+		return ""
+	}
+	return v.PreRelease[0]
+}
+
+// preReleaseNumber returns the numeric counter following the channel
+// identifier, or 0 if there is none
+func (v *Version) preReleaseNumber() int {
+	if len(v.PreRelease) < 2 || !isNumeric(v.PreRelease[1]) {
+		return 0
+	}
+	n, _ := strconv.Atoi(v.PreRelease[1])
+	return n
+}
+
+// Next returns the next normal version for the given increment type
+// ("major", "minor" or "patch")
+func (v *Version) Next(incrementType string) *Version {
+	switch incrementType {
+	case "major":
+		return v.NextMajor()
+	case "patch":
+		return v.NextPatch()
+	default:
+		return v.NextMinor()
+	}
+}
+
+// incrementRanks orders the increment types by size
+var incrementRanks = map[string]int{"patch": 1, "minor": 2, "major": 3}
+
+// coreIncrement returns the increment type the core version of a
+// pre-release was planned with (1.5.1-rc.1 is a patch, 1.5.0-rc.1 a minor
+// and 2.0.0-rc.1 a major)
+func (v *Version) coreIncrement() string {
+	switch {
+	case v.Patch != 0:
+		return "patch"
+	case v.Minor != 0:
+		return "minor"
+	default:
+		return "major"
+	}
+}
+
+// NextPreRelease returns the next pre-release version on the given channel.
+// If v is already a pre-release on that channel its counter is incremented
+// (1.5.0-rc.1 -> 1.5.0-rc.2). Moving to a later channel keeps the same core
+// version (1.5.0-beta.3 -> 1.5.0-rc.1). Otherwise the core version is
+// incremented by incrementType and the counter starts at 1.
+//
+// An incrementType larger than the one the core version of a pre-release
+// was planned with recomputes the core (1.5.0-rc.1 -> 2.0.0-rc.1 for
+// "major"). An empty incrementType keeps the core of a pre-release and
+// increments the minor version otherwise.
+func (v *Version) NextPreRelease(incrementType, channel string) *Version {
+	if v.IsPreRelease() && incrementRanks[incrementType] > incrementRanks[v.coreIncrement()] {
+		next := v.Next(incrementType)
+		next.PreRelease = []string{channel, "1"}
+		return next
+	}
+
+	if v.IsPreRelease() {
+		if v.Channel() == channel {
+			next := v.Core()
+			next.PreRelease = []string{channel, strconv.Itoa(v.preReleaseNumber() + 1)}
+			return next
+		}
+
+		candidate := v.Core()
+		candidate.PreRelease = []string{channel, "1"}
+		if v.LessThan(candidate) {
+			return candidate
+		}
+
+		// Moving back to an earlier channel requires a new core version
+		next := v.Core().Next(incrementType)
+		next.PreRelease = []string{channel, "1"}
+		return next
+	}
+
+	next := v.Next(incrementType)
+	next.PreRelease = []string{channel, "1"}
+	return next
+}
+
+// InitialPreRelease returns the first pre-release version on a channel for
+// a repository with no recorded versions
+func InitialPreRelease(incrementType, channel string) string {
+	return Initial(incrementType) + "-" + channel + ".1"
+}
+
+// Line is a MAJOR.MINOR release line, such as 1.8, whose versions only
+// receive patch releases
+type Line struct {
+	Major int
+	Minor int
+}
+
+// ParseLine parses a release line of the form MAJOR.MINOR (with or without
+// 'v' prefix)
+func ParseLine(s string) (Line, error) {
+	parts := strings.Split(strings.TrimPrefix(s, "v"), ".")
+	if len(parts) != 2 {
+		return Line{}, fmt.Errorf("invalid release line %q (expected MAJOR.MINOR)", s)
+	}
+
+	major, err := parseNumber(parts[0])
+	if err != nil {
+		return Line{}, fmt.Errorf("invalid release line %q: %w", s, err)
+	}
+	minor, err := parseNumber(parts[1])
+	if err != nil {
+		return Line{}, fmt.Errorf("invalid release line %q: %w", s, err)
+	}
+
+	return Line{Major: major, Minor: minor}, nil
+}
+
+// String formats the line as MAJOR.MINOR
+func (l Line) String() string {
+	return fmt.Sprintf("%d.%d", l.Major, l.Minor)
+}
+
+// Line returns the release line the version belongs to
+func (v *Version) Line() Line {
+	return Line{Major: v.Major, Minor: v.Minor}
+}
+
+// Contains reports whether v belongs to the release line
+func (l Line) Contains(v *Version) bool {
+	return v.Major == l.Major && v.Minor == l.Minor
+}
@@ -0,0 +1,147 @@
+package version
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"1.2.3", "1.2.3"},
+		{"v1.2.3", "1.2.3"},
+		{"0.0.0", "0.0.0"},
+		{"1.0.0-alpha", "1.0.0-alpha"},
+		{"1.0.0-alpha.1", "1.0.0-alpha.1"},
+		{"1.0.0-0.3.7", "1.0.0-0.3.7"},
+		{"1.0.0-x-y-z.--", "1.0.0-x-y-z.--"},
+		{"1.0.0+001", "1.0.0+001"},
+		{"1.0.0-beta+exp.sha.5114f85", "1.0.0-beta+exp.sha.5114f85"},
+		{"1.0.0+21AF26D3----117B344092BD", "1.0.0+21AF26D3----117B344092BD"},
+	}
+
+	for _, tt := range tests {
+		v, err := Parse(tt.input)
+		if err != nil {
+			t.Errorf("Parse(%q) returned error: %v", tt.input, err)
+			continue
+		}
+		if got := v.String(); got != tt.want {
+			t.Errorf("Parse(%q).String() = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"1",
+		"1.2",
+		"1.2.3.4",
+		"01.2.3",
+		"1.02.3",
+		"1.2.03",
+		"-1.2.3",
+		"1.2.x",
+		"1.2.3-",
+		"1.2.3-01",
+		"1.2.3-alpha..1",
+		"1.2.3-alpha.",
+		"1.2.3+",
+		"1.2.3+build..1",
+		"1.2.3-alpha_1",
+		"1.2.3+build$",
+	}
+
+	for _, input := range tests {
+		if v, err := Parse(input); err == nil {
+			t.Errorf("Parse(%q) = %s, want error", input, v)
+		}
+	}
+}
+
+func TestComparePrecedence(t *testing.T) {
+	// Ascending precedence from the Semantic Versioning 2.0.0 specification
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.1.0",
+		"2.0.0",
+		"2.1.0",
+		"2.1.1",
+	}
+
+	for i := range ordered {
+		for j := range ordered {
+			want := compareInt(i, j)
+			got, err := Compare(ordered[i], ordered[j])
+			if err != nil {
+				t.Fatalf("Compare(%q, %q) returned error: %v", ordered[i], ordered[j], err)
+			}
+			if got != want {
+				t.Errorf("Compare(%q, %q) = %d, want %d", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+}
+
+func TestCompareIgnoresBuildMetadata(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"1.0.0", "1.0.0+20130313144700"},
+		{"1.0.0+build.1", "1.0.0+build.2"},
+		{"1.0.0-beta+exp.sha.5114f85", "1.0.0-beta"},
+		{"1.0.0-rc.1+a", "1.0.0-rc.1+b"},
+	}
+
+	for _, tt := range tests {
+		got, err := Compare(tt.a, tt.b)
+		if err != nil {
+			t.Fatalf("Compare(%q, %q) returned error: %v", tt.a, tt.b, err)
+		}
+		if got != 0 {
+			t.Errorf("Compare(%q, %q) = %d, want 0", tt.a, tt.b, got)
+		}
+	}
+}
+
+func TestNextPreRelease(t *testing.T) {
+	tests := []struct {
+		current   string
+		increment string
+		channel   string
+		want      string
+	}{
+		{"1.2.0", "minor", "rc", "1.3.0-rc.1"},
+		{"1.2.0", "major", "rc", "2.0.0-rc.1"},
+		{"1.2.0", "patch", "rc", "1.2.1-rc.1"},
+		{"1.2.0", "", "rc", "1.3.0-rc.1"},
+		{"1.3.0-rc.1", "", "rc", "1.3.0-rc.2"},
+		{"1.3.0-rc.1", "minor", "rc", "1.3.0-rc.2"},
+		{"1.3.0-rc.1", "patch", "rc", "1.3.0-rc.2"},
+		{"1.3.0-rc.1", "major", "rc", "2.0.0-rc.1"},
+		{"1.3.1-rc.1", "minor", "rc", "1.4.0-rc.1"},
+		{"1.3.1-rc.2", "patch", "rc", "1.3.1-rc.3"},
+		{"2.0.0-rc.1", "major", "rc", "2.0.0-rc.2"},
+		{"1.3.0-beta.3", "", "rc", "1.3.0-rc.1"},
+		{"1.3.0-beta.3", "major", "rc", "2.0.0-rc.1"},
+		{"1.3.0-rc.2", "", "beta", "1.4.0-beta.1"},
+	}
+
+	for _, tt := range tests {
+		v, err := Parse(tt.current)
+		if err != nil {
+			t.Fatalf("Parse(%q) returned error: %v", tt.current, err)
+		}
+		if got := v.NextPreRelease(tt.increment, tt.channel).String(); got != tt.want {
+			t.Errorf("%s.NextPreRelease(%q, %q) = %s, want %s", tt.current, tt.increment, tt.channel, got, tt.want)
+		}
+	}
+}